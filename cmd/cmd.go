@@ -13,10 +13,13 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
@@ -30,18 +33,52 @@ import (
 )
 
 const (
+	Reload  = 10 * time.Second
 	Timeout = 120 * time.Second
 )
 
+var (
+	codes   = []string{flow.ExitCodeNone, flow.ExitCodeError, flow.ExitCodeWarn, flow.ExitCodeInfo}
+	formats = []string{flow.OutputCheckstyle, flow.OutputCodeclimate, flow.OutputJson, flow.OutputJunit, flow.OutputText, flow.OutputPretty}
+)
+
 var (
 	app        = kingpin.New("lintflow", "Lint Flow").Version(config.Version + "-build-" + config.Build)
-	codeReview = app.Flag("code-review", "Code review (bitbucket|gerrit|gitee|github|gitlab)").Required().String()
-	commitHash = app.Flag("commit-hash", "Commit hash (SHA-1)").Required().String()
 	configFile = app.Flag("config-file", "Config file (.yml)").Required().String()
 )
 
+var (
+	runCmd       = app.Command("run", "Run flow").Default()
+	codeReview   = runCmd.Flag("code-review", "Code review (bitbucket|gerrit|gitee|github|gitlab)").Required().String()
+	commitHash   = runCmd.Flag("commit-hash", "Commit hash (SHA-1)").Required().String()
+	exitCode     = runCmd.Flag("exit-code", "Exit code on findings ("+strings.Join(codes, "|")+")").Default(flow.ExitCodeNone).Enum(codes...)
+	outputFile   = runCmd.Flag("output-file", "Output file").String()
+	outputFormat = runCmd.Flag("output-format", "Output format ("+strings.Join(formats, "|")+")").Default(flow.OutputPretty).Enum(formats...)
+	sarifFile    = runCmd.Flag("sarif-file", "SARIF file (.sarif)").String()
+)
+
+var (
+	serveCmd     = app.Command("serve", "Run flows for the commit hashes read from stdin, reloading config file on change")
+	reloadPeriod = serveCmd.Flag("reload-interval", "Interval of checking config file for changes").Default(Reload.String()).Duration()
+)
+
+var (
+	configCmd   = app.Command("config", "Config commands")
+	validateCmd = configCmd.Command("validate", "Validate config file")
+	printConfig = validateCmd.Flag("print", "Print resolved config with secrets redacted").Bool()
+)
+
 func Run(ctx context.Context) error {
-	kingpin.MustParse(app.Parse(os.Args[1:]))
+	switch kingpin.MustParse(app.Parse(os.Args[1:])) {
+	case validateCmd.FullCommand():
+		return validateConfig(*configFile, *printConfig)
+	case serveCmd.FullCommand():
+		h, err := config.NewHolder(*configFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to init config")
+		}
+		return serve(ctx, h, os.Stdin, *reloadPeriod, runCommit)
+	}
 
 	c, err := initConfig(*configFile)
 	if err != nil {
@@ -60,7 +97,10 @@ func Run(ctx context.Context) error {
 
 	log.Println("flow running")
 
-	if err := runFlow(ctx, c, r, l); err != nil {
+	if err := runFlow(ctx, c, r, l, *commitHash); err != nil {
+		if errors.Is(err, flow.ErrFindings) {
+			return err
+		}
 		return errors.Wrap(err, "failed to run flow")
 	}
 
@@ -70,30 +110,42 @@ func Run(ctx context.Context) error {
 }
 
 func initConfig(name string) (*config.Config, error) {
-	c := config.New()
-	if c == nil {
-		return &config.Config{}, errors.New("failed to new")
-	}
-
-	fi, err := os.Open(name)
+	c, err := config.Load(name)
 	if err != nil {
-		return c, errors.Wrap(err, "failed to open")
+		return nil, errors.Wrap(err, "failed to load")
 	}
 
-	defer func() {
-		_ = fi.Close()
-	}()
+	return c, nil
+}
 
-	buf, err := io.ReadAll(fi)
+func validateConfig(name string, dump bool) error {
+	c, err := config.Load(name)
 	if err != nil {
-		return c, errors.Wrap(err, "failed to readall")
+		var e *config.ValidateError
+		if !errors.As(err, &e) {
+			return errors.Wrap(err, "failed to load")
+		}
+		for _, item := range e.Problems {
+			file := name
+			if item.File != "" {
+				file = item.File
+			}
+			fmt.Printf("%s:%d: %s\n", file, item.Line, item.Message)
+		}
+		return errors.Errorf("%d problems found", len(e.Problems))
 	}
 
-	if err := yaml.Unmarshal(buf, c); err != nil {
-		return c, errors.Wrap(err, "failed to unmarshal")
+	if dump {
+		buf, err := yaml.Marshal(c.Redact())
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal")
+		}
+		fmt.Print(string(buf))
 	}
 
-	return c, nil
+	fmt.Printf("%s: valid\n", name)
+
+	return nil
 }
 
 func initReview(cfg *config.Config) (review.Review, error) {
@@ -103,6 +155,7 @@ func initReview(cfg *config.Config) (review.Review, error) {
 	}
 
 	c.Review = cfg.Spec.Review
+	c.Override = cfg.Spec.Flow.Override.File
 
 	return review.New(c), nil
 }
@@ -118,7 +171,54 @@ func initLint(cfg *config.Config) (lint.Lint, error) {
 	return lint.New(c), nil
 }
 
-func runFlow(ctx context.Context, c *config.Config, r review.Review, l lint.Lint) error {
+// serve runs a flow for each commit hash read from in until it is closed, with a snapshot of the config
+// taken per commit, while the config holder is watched. Failed flows are logged.
+func serve(ctx context.Context, h *config.Holder, in io.Reader, interval time.Duration,
+	run func(context.Context, *config.Config, string) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go h.Watch(ctx, interval)
+
+	log.Println("serve running")
+
+	s := bufio.NewScanner(in)
+
+	for s.Scan() {
+		commit := strings.TrimSpace(s.Text())
+		if commit == "" {
+			continue
+		}
+		if err := run(ctx, h.Get(), commit); err != nil {
+			log.Printf("flow %s: %v\n", commit, err)
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return errors.Wrap(err, "failed to scan")
+	}
+
+	log.Println("serve exiting")
+
+	return nil
+}
+
+// runCommit runs a flow of commit with the review and lints of c.
+func runCommit(ctx context.Context, c *config.Config, commit string) error {
+	r, err := initReview(c)
+	if err != nil {
+		return errors.Wrap(err, "failed to init review")
+	}
+
+	l, err := initLint(c)
+	if err != nil {
+		return errors.Wrap(err, "failed to init lint")
+	}
+
+	return runFlow(ctx, c, r, l, commit)
+}
+
+func runFlow(ctx context.Context, c *config.Config, r review.Review, l lint.Lint, commit string) error {
 	cfg := flow.DefaultConfig()
 	if cfg == nil {
 		return errors.New("failed to config flow")
@@ -127,6 +227,10 @@ func runFlow(ctx context.Context, c *config.Config, r review.Review, l lint.Lint
 	cfg.Config = *c
 	cfg.Lint = l
 	cfg.Review = r
+	cfg.ExitCode = *exitCode
+	cfg.OutputFile = *outputFile
+	cfg.OutputFormat = *outputFormat
+	cfg.SarifFile = *sarifFile
 
 	f := flow.New(context.Background(), cfg)
 	if f == nil {
@@ -141,7 +245,10 @@ func runFlow(ctx context.Context, c *config.Config, r review.Review, l lint.Lint
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	if err = f.Run(ctx, *commitHash); err != nil {
+	if err = f.Run(ctx, commit); err != nil {
+		if errors.Is(err, flow.ErrFindings) {
+			return err
+		}
 		return errors.Wrap(err, "failed to run flow")
 	}
 
@@ -13,12 +13,47 @@
 package cmd
 
 import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-lintflow/lintflow/config"
+	"github.com/devops-lintflow/lintflow/flow"
+	"github.com/devops-lintflow/lintflow/format"
 )
 
+type fakeReview struct{}
+
+func (r fakeReview) Clean(string) error {
+	return nil
+}
+
+// nolint:gocritic
+func (r fakeReview) Fetch(context.Context, string, string,
+	func(string, string, string) bool) (string, string, []string, string, string, error) {
+	return "../tests/project", "repo", []string{"lintshell/test.sh"}, "42-a4bc7bd.meta", "42-a4bc7bd.patch", nil
+}
+
+func (r fakeReview) Vote(context.Context, string, []format.Report, config.Vote) error {
+	return nil
+}
+
+type fakeLint struct{}
+
+func (l fakeLint) Run(context.Context, string, string, []string, string, string,
+	func(*config.Lint, string, string) bool) (map[string][]format.Report, map[string]time.Duration, error) {
+	return map[string][]format.Report{
+		"lintshell": {{File: "lintshell/test.sh", Line: 1, Type: format.TypeError, Details: "error"}},
+	}, nil, nil
+}
+
 func TestInitConfig(t *testing.T) {
 	var err error
 
@@ -32,6 +67,44 @@ func TestInitConfig(t *testing.T) {
 	assert.Equal(t, nil, err)
 }
 
+func TestValidateConfig(t *testing.T) {
+	err := validateConfig("../tests/invalid.yml", false)
+	assert.NotEqual(t, nil, err)
+
+	err = validateConfig("../tests/invalid_schema.yml", false)
+	assert.NotEqual(t, nil, err)
+
+	err = validateConfig("../tests/config.yml", true)
+	assert.Equal(t, nil, err)
+
+	dir := t.TempDir()
+
+	base := "apiVersion: v1\nkind: server\nspec:\n  flow:\n    timeout: 120s\n    bogus: true\n"
+
+	err = os.WriteFile(filepath.Join(dir, "base.yml"), []byte(base), 0600)
+	assert.Equal(t, nil, err)
+
+	err = os.WriteFile(filepath.Join(dir, "main.yml"), []byte("apiVersion: v1\nkind: server\ninclude:\n  - base.yml\n"), 0600)
+	assert.Equal(t, nil, err)
+
+	stdout := os.Stdout
+
+	r, w, err := os.Pipe()
+	assert.Equal(t, nil, err)
+
+	os.Stdout = w
+
+	err = validateConfig(filepath.Join(dir, "main.yml"), false)
+
+	os.Stdout = stdout
+	_ = w.Close()
+
+	assert.NotEqual(t, nil, err)
+
+	buf, _ := io.ReadAll(r)
+	assert.Contains(t, string(buf), filepath.Join(dir, "base.yml")+":6: unknown key \"bogus\"")
+}
+
 func TestInitReview(t *testing.T) {
 	c, err := initConfig("../tests/config.yml")
 	assert.Equal(t, nil, err)
@@ -48,6 +121,39 @@ func TestInitLint(t *testing.T) {
 	assert.Equal(t, nil, err)
 }
 
+func TestRunFlow(t *testing.T) {
+	c, err := initConfig("../tests/config.yml")
+	assert.Equal(t, nil, err)
+
+	*outputFile = t.TempDir() + "/output.json"
+
+	*exitCode = flow.ExitCodeNone
+	err = runFlow(context.Background(), c, fakeReview{}, fakeLint{}, "a4bc7bd")
+	assert.Equal(t, nil, err)
+
+	*exitCode = flow.ExitCodeError
+	err = runFlow(context.Background(), c, fakeReview{}, fakeLint{}, "a4bc7bd")
+	assert.Equal(t, true, errors.Is(err, flow.ErrFindings))
+	assert.Equal(t, flow.ErrFindings.Error(), err.Error())
+}
+
+func TestServe(t *testing.T) {
+	h, err := config.NewHolder("../tests/config.yml")
+	assert.Equal(t, nil, err)
+
+	var commits []string
+
+	run := func(_ context.Context, c *config.Config, commit string) error {
+		assert.Equal(t, h.Get(), c)
+		commits = append(commits, commit)
+		return errors.New("failed to run")
+	}
+
+	err = serve(context.Background(), h, strings.NewReader("a4bc7bd\n\n 533cf5c \n"), time.Minute, run)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"a4bc7bd", "533cf5c"}, commits)
+}
+
 func TestSetTimeout(t *testing.T) {
 	timeout, err := setTimeout("")
 	assert.Equal(t, nil, err)
@@ -15,6 +15,7 @@ package config
 type Config struct {
 	ApiVersion string   `yaml:"apiVersion"`
 	Kind       string   `yaml:"kind"`
+	Include    []string `yaml:"include"`
 	MetaData   MetaData `yaml:"metadata"`
 	Spec       Spec     `yaml:"spec"`
 }
@@ -24,46 +25,115 @@ type MetaData struct {
 }
 
 type Spec struct {
-	Flow   Flow   `yaml:"flow"`
-	Lints  []Lint `yaml:"lint"`
-	Review Review `yaml:"review"`
+	Flow      Flow   `yaml:"flow"`
+	Defaults  Lint   `yaml:"defaults"`
+	Templates []Lint `yaml:"template"`
+	Lints     []Lint `yaml:"lint"`
+	Review    Review `yaml:"review"`
 }
 
 type Flow struct {
-	Timeout string `yaml:"timeout"`
+	Timeout  string   `yaml:"timeout"`
+	Override Override `yaml:"override"`
+	Report   Report   `yaml:"report"`
+}
+
+type Override struct {
+	File     string `yaml:"file"`
+	Disable  bool   `yaml:"disable"`
+	Exclude  bool   `yaml:"exclude"`
+	Severity string `yaml:"severity"`
+}
+
+type Report struct {
+	Dir string `yaml:"dir"`
+	Url string `yaml:"url"`
 }
 
 type Lint struct {
-	Name   string `yaml:"name"`
-	Host   string `yaml:"host"`
-	Port   int    `yaml:"port"`
-	Filter Filter `yaml:"filter"`
-	Vote   string `yaml:"vote"`
+	Name     string            `yaml:"name"`
+	Host     string            `yaml:"host"`
+	Port     int               `yaml:"port"`
+	Command  Command           `yaml:"command"`
+	Extends  []string          `yaml:"extends"`
+	Disable  bool              `yaml:"disable"`
+	Filter   Filter            `yaml:"filter"`
+	Severity map[string]string `yaml:"severity"`
+	Vote     string            `yaml:"vote"`
+}
+
+type Command struct {
+	Exec        []string `yaml:"exec"`
+	Format      string   `yaml:"format"`
+	Errorformat []string `yaml:"errorformat"`
 }
 
 type Filter struct {
-	Include Include `yaml:"include"`
+	Include Match `yaml:"include"`
+	Exclude Match `yaml:"exclude"`
 }
 
-type Include struct {
+type Match struct {
 	Extensions []string `yaml:"extension"`
 	Files      []string `yaml:"file"`
+	Paths      []string `yaml:"path"`
 	Repos      []string `yaml:"repo"`
+	Branches   []string `yaml:"branch"`
 }
 
 type Review struct {
-	Name  string `yaml:"name"`
-	Url   string `yaml:"url"`
-	User  string `yaml:"user"`
-	Pass  string `yaml:"pass"`
-	Votes []Vote `yaml:"vote"`
+	Name       string  `yaml:"name"`
+	Url        string  `yaml:"url"`
+	User       string  `yaml:"user"`
+	Pass       string  `yaml:"pass"`
+	PassFile   string  `yaml:"passFile"`
+	Token      string  `yaml:"token"`
+	TokenFile  string  `yaml:"tokenFile"`
+	Cookie     string  `yaml:"cookie"`
+	CookieFile string  `yaml:"cookieFile"`
+	Http       Http    `yaml:"http"`
+	Fetch      Fetch   `yaml:"fetch"`
+	Comment    Comment `yaml:"comment"`
+	Votes      []Vote  `yaml:"vote"`
+}
+
+type Http struct {
+	Timeout   string  `yaml:"timeout"`
+	CaFile    string  `yaml:"caFile"`
+	Insecure  bool    `yaml:"insecure"`
+	Proxy     string  `yaml:"proxy"`
+	UserAgent string  `yaml:"userAgent"`
+	Retries   int     `yaml:"retries"`
+	Rate      float64 `yaml:"rate"`
+}
+
+type Fetch struct {
+	Workers int    `yaml:"workers"`
+	Archive bool   `yaml:"archive"`
+	Base    bool   `yaml:"base"`
+	Mirror  string `yaml:"mirror"`
+}
+
+type Comment struct {
+	MaxPerRun    int    `yaml:"maxPerRun"`
+	MaxPerChange int    `yaml:"maxPerChange"`
+	Unresolved   string `yaml:"unresolved"`
+	Fallback     string `yaml:"fallback"`
 }
 
 type Vote struct {
-	Label       string `yaml:"label"`
-	Approval    string `yaml:"approval"`
-	Disapproval string `yaml:"disapproval"`
-	Message     string `yaml:"message"`
+	Label       string   `yaml:"label"`
+	Approval    string   `yaml:"approval"`
+	Disapproval string   `yaml:"disapproval"`
+	Message     string   `yaml:"message"`
+	Branches    []string `yaml:"branch"`
+	Policy      Policy   `yaml:"policy"`
+}
+
+type Policy struct {
+	Severity  string            `yaml:"severity"`
+	Threshold map[string]int    `yaml:"threshold"`
+	Score     map[string]string `yaml:"score"`
 }
 
 var (
@@ -13,8 +13,13 @@
 package config
 
 import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,3 +27,120 @@ func TestConfig(t *testing.T) {
 	cfg := New()
 	assert.NotEqual(t, nil, cfg)
 }
+
+func TestLoad(t *testing.T) {
+	_, err := Load("invalid.yml")
+	assert.NotEqual(t, nil, err)
+
+	c, err := Load("../tests/config.yml")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, KindServer, c.Kind)
+
+	_, err = Load("../tests/invalid.yml")
+	assert.NotEqual(t, nil, err)
+
+	_, err = Load("../tests/invalid_schema.yml")
+	assert.NotEqual(t, nil, err)
+
+	var e *ValidateError
+
+	assert.Equal(t, true, errors.As(err, &e))
+	assert.Equal(t, []Problem{
+		{Line: 2, Message: `invalid kind "service", expected "server"`},
+		{Line: 7, Message: `invalid duration "120"`},
+		{Line: 9, Message: `invalid port 0 of lint "lintai"`},
+		{Line: 50, Message: `unknown key "timeout" in spec.lint[2]`},
+		{Line: 94, Message: `empty filter of lint "lintmake"`},
+		{Line: 118, Message: `vote "Shell-Verified" of lint "lintshell" not found in spec.review.vote`},
+	}, e.Problems)
+}
+
+func TestParse(t *testing.T) {
+	_, err := Parse([]byte("spec: ["))
+	assert.NotEqual(t, nil, err)
+
+	_, err = Parse([]byte(""))
+	assert.NotEqual(t, nil, err)
+
+	data := `apiVersion: v1
+kind: server
+spec:
+  lint:
+    - name: lintshell
+      port: invalid
+      command:
+        exec:
+          - shellcheck
+      filter:
+        include:
+          extension:
+            - .sh
+          repo:
+            - "("
+      severity:
+        Error: Fatal
+  review:
+    url: http://127.0.0.1:8080
+    http:
+      timeout: 1x
+      proxy: 127.0.0.1
+      retries: -2
+    comment:
+      fallback: drop
+`
+
+	_, err = Parse([]byte(data))
+	assert.NotEqual(t, nil, err)
+
+	var e *ValidateError
+
+	assert.Equal(t, true, errors.As(err, &e))
+	assert.Equal(t, 7, len(e.Problems))
+	assert.Equal(t, 6, e.Problems[0].Line)
+	assert.Equal(t, 15, e.Problems[1].Line)
+	assert.Equal(t, `invalid pattern "("`, e.Problems[1].Message)
+	assert.Equal(t, 17, e.Problems[2].Line)
+	assert.Equal(t, 21, e.Problems[3].Line)
+	assert.Equal(t, `invalid duration "1x"`, e.Problems[3].Message)
+	assert.Equal(t, 22, e.Problems[4].Line)
+	assert.Equal(t, `invalid proxy "127.0.0.1"`, e.Problems[4].Message)
+	assert.Equal(t, 23, e.Problems[5].Line)
+	assert.Equal(t, `invalid retries -2`, e.Problems[5].Message)
+	assert.Equal(t, 25, e.Problems[6].Line)
+}
+
+func TestSchema(t *testing.T) {
+	buf, err := os.ReadFile("config.schema.json")
+	assert.Equal(t, nil, err)
+
+	schema := map[string]interface{}{}
+	err = json.Unmarshal(buf, &schema)
+	assert.Equal(t, nil, err)
+
+	var helper func(reflect.Type, map[string]interface{}, string)
+
+	helper = func(t1 reflect.Type, node map[string]interface{}, path string) {
+		if ref, ok := node["$ref"].(string); ok {
+			node = schema["$defs"].(map[string]interface{})[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+		}
+		switch t1.Kind() {
+		case reflect.Struct:
+			props, _ := node["properties"].(map[string]interface{})
+			for i := 0; i < t1.NumField(); i++ {
+				name := t1.Field(i).Tag.Get("yaml")
+				val, ok := props[name].(map[string]interface{})
+				assert.Equal(t, true, ok, path+"."+name)
+				if ok {
+					helper(t1.Field(i).Type, val, path+"."+name)
+				}
+			}
+		case reflect.Slice:
+			if t1.Elem().Kind() == reflect.Struct {
+				helper(t1.Elem(), node["items"].(map[string]interface{}), path)
+			}
+		default:
+		}
+	}
+
+	helper(reflect.TypeOf(Config{}), schema, "")
+}
@@ -0,0 +1,193 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// load decodes a config file into a node with environment variables expanded, and merges
+// the files of its include list beneath it, relative paths are relative to dir.
+func (v *validator) load(data []byte, file, dir string, seen map[string]bool) (*yaml.Node, error) {
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, &ValidateError{Problems: []Problem{{File: file, Line: 1, Message: "empty config"}}}
+	}
+
+	node := doc.Content[0]
+
+	if file != "" {
+		v.mark(node, file)
+	}
+
+	v.expand(node)
+
+	include := v.value(node, "include")
+	if include == nil || include.Kind != yaml.SequenceNode {
+		return node, nil
+	}
+
+	var base *yaml.Node
+
+	for _, item := range include.Content {
+		name := item.Value
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(dir, name)
+		}
+		if seen[name] {
+			v.add(item, "include cycle of %q", item.Value)
+			continue
+		}
+		buf, err := os.ReadFile(name)
+		if err != nil {
+			v.add(item, "failed to include %q", item.Value)
+			continue
+		}
+		seen[name] = true
+		v.includes = append(v.includes, name)
+		n, err := v.load(buf, name, filepath.Dir(name), seen)
+		delete(seen, name)
+		if err != nil {
+			var e *ValidateError
+			if errors.As(err, &e) {
+				v.problems = append(v.problems, e.Problems...)
+			} else {
+				v.add(item, "failed to include %q: %v", item.Value, err)
+			}
+			continue
+		}
+		base = v.merge(base, n)
+	}
+
+	return v.merge(base, node), nil
+}
+
+// templates merges spec.defaults and the spec.template entries named in extends beneath each lint.
+func (v *validator) templates(node *yaml.Node) {
+	spec := v.value(node, "spec")
+	if spec == nil {
+		return
+	}
+
+	lints := v.value(spec, "lint")
+	if lints == nil || lints.Kind != yaml.SequenceNode {
+		return
+	}
+
+	defaults := v.value(spec, "defaults")
+	templates := map[string]*yaml.Node{}
+
+	if t := v.value(spec, "template"); t != nil && t.Kind == yaml.SequenceNode {
+		for _, item := range t.Content {
+			if name := v.value(item, "name"); name != nil {
+				templates[name.Value] = item
+			}
+		}
+	}
+
+	for i, item := range lints.Content {
+		base := defaults
+		if extends := v.value(item, "extends"); extends != nil && extends.Kind == yaml.SequenceNode {
+			for _, name := range extends.Content {
+				if t, ok := templates[name.Value]; ok {
+					base = v.merge(base, t)
+				} else {
+					v.add(name, "template %q not found in spec.template", name.Value)
+				}
+			}
+		}
+		lints.Content[i] = v.merge(base, item)
+	}
+}
+
+// merge merges over into base: mappings are merged by key, sequences are appended to and
+// other values are replaced, except with null.
+func (v *validator) merge(base, over *yaml.Node) *yaml.Node {
+	if base == nil {
+		return over
+	}
+
+	if base.Kind == yaml.AliasNode && base.Alias != nil {
+		base = base.Alias
+	}
+
+	if over.Kind == yaml.AliasNode && over.Alias != nil {
+		over = over.Alias
+	}
+
+	if over.Kind == yaml.ScalarNode && over.ShortTag() == "!!null" {
+		return base
+	}
+
+	if base.Kind != over.Kind || (over.Kind != yaml.MappingNode && over.Kind != yaml.SequenceNode) {
+		return over
+	}
+
+	buf := *over
+	buf.Content = append([]*yaml.Node{}, base.Content...)
+
+	if over.Kind == yaml.SequenceNode {
+		buf.Content = append(buf.Content, over.Content...)
+	} else {
+		for i := 0; i+1 < len(over.Content); i += 2 {
+			found := false
+			for j := 0; j+1 < len(buf.Content); j += 2 {
+				if buf.Content[j].Value == over.Content[i].Value {
+					buf.Content[j+1] = v.merge(buf.Content[j+1], over.Content[i+1])
+					found = true
+					break
+				}
+			}
+			if !found {
+				buf.Content = append(buf.Content, over.Content[i], over.Content[i+1])
+			}
+		}
+	}
+
+	if file, ok := v.files[over]; ok {
+		v.files[&buf] = file
+	}
+
+	return &buf
+}
+
+func (v *validator) mark(node *yaml.Node, file string) {
+	v.files[node] = file
+
+	for _, item := range node.Content {
+		v.mark(item, file)
+	}
+}
+
+func (v *validator) value(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
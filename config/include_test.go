@@ -0,0 +1,139 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	includeBase = `apiVersion: v1
+kind: server
+spec:
+  flow:
+    timeout: 120s
+  defaults:
+    host: 127.0.0.1
+    port: 9090
+  template:
+    - name: cpp
+      filter:
+        include:
+          extension:
+            - .cc
+            - .cpp
+        exclude:
+          path:
+            - third_party/**
+  lint:
+    - name: lintcommit
+      filter:
+        include:
+          file:
+            - COMMIT_MSG
+      vote: Verified
+  review:
+    url: http://127.0.0.1:8080
+    vote:
+      - label: Verified
+        approval: +1
+        disapproval: -1
+`
+
+	includeConfig = `apiVersion: v1
+kind: server
+include:
+  - base.yml
+spec:
+  flow:
+    timeout: 60s
+  lint:
+    - name: lintcpp
+      port: 9091
+      extends:
+        - cpp
+      filter:
+        include:
+          extension:
+            - .h
+      vote: Verified
+`
+)
+
+func TestInclude(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "base.yml"), []byte(includeBase), 0600)
+	assert.Equal(t, nil, err)
+
+	err = os.WriteFile(filepath.Join(dir, "config.yml"), []byte(includeConfig), 0600)
+	assert.Equal(t, nil, err)
+
+	c, err := Load(filepath.Join(dir, "config.yml"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "60s", c.Spec.Flow.Timeout)
+	assert.Equal(t, 2, len(c.Spec.Lints))
+	assert.Equal(t, "lintcommit", c.Spec.Lints[0].Name)
+	assert.Equal(t, "127.0.0.1", c.Spec.Lints[0].Host)
+	assert.Equal(t, 9090, c.Spec.Lints[0].Port)
+	assert.Equal(t, "lintcpp", c.Spec.Lints[1].Name)
+	assert.Equal(t, "127.0.0.1", c.Spec.Lints[1].Host)
+	assert.Equal(t, 9091, c.Spec.Lints[1].Port)
+	assert.Equal(t, []string{".cc", ".cpp", ".h"}, c.Spec.Lints[1].Filter.Include.Extensions)
+	assert.Equal(t, []string{"third_party/**"}, c.Spec.Lints[1].Filter.Exclude.Paths)
+	assert.Equal(t, []string{".cc", ".cpp"}, c.Spec.Templates[0].Filter.Include.Extensions)
+}
+
+func TestIncludeInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "base.yml"), []byte(includeBase+"    unknown: true\n"), 0600)
+	assert.Equal(t, nil, err)
+
+	data := `apiVersion: v1
+kind: server
+include:
+  - base.yml
+  - config.yml
+  - missing.yml
+spec:
+  lint:
+    - name: lintjava
+      extends:
+        - java
+      filter:
+        include:
+          extension:
+            - .java
+`
+
+	err = os.WriteFile(filepath.Join(dir, "config.yml"), []byte(data), 0600)
+	assert.Equal(t, nil, err)
+
+	_, err = Load(filepath.Join(dir, "config.yml"))
+
+	var e *ValidateError
+
+	assert.Equal(t, true, errors.As(err, &e))
+	assert.Equal(t, []Problem{
+		{Line: 5, Message: `include cycle of "config.yml"`},
+		{Line: 6, Message: `failed to include "missing.yml"`},
+		{Line: 11, Message: `template "java" not found in spec.template`},
+		{File: filepath.Join(dir, "base.yml"), Line: 32, Message: `unknown key "unknown" in spec.review`},
+	}, e.Problems)
+}
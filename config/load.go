@@ -0,0 +1,397 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/devops-lintflow/lintflow/format"
+)
+
+const (
+	ApiVersion = "v1"
+	KindServer = "server"
+)
+
+var (
+	fallbacks  = []string{"", "discard", "file", "message"}
+	severities = []string{format.TypeError, format.TypeInfo, format.TypeWarn}
+)
+
+// Problem is an invalid setting of a config file, File is set for included files.
+type Problem struct {
+	File    string
+	Line    int
+	Message string
+}
+
+// ValidateError is returned by Load with every problem of a config file.
+type ValidateError struct {
+	Problems []Problem
+}
+
+func (e *ValidateError) Error() string {
+	buf := make([]string, 0, len(e.Problems))
+
+	for _, item := range e.Problems {
+		if item.File != "" {
+			buf = append(buf, fmt.Sprintf("%s: line %d: %s", item.File, item.Line, item.Message))
+		} else {
+			buf = append(buf, fmt.Sprintf("line %d: %s", item.Line, item.Message))
+		}
+	}
+
+	return "invalid config:\n" + strings.Join(buf, "\n")
+}
+
+// Load reads a config file strictly, see Parse, and the secrets of passFile, tokenFile and cookieFile.
+func Load(name string) (*Config, error) {
+	c, _, err := load(name)
+
+	return c, err
+}
+
+// Parse decodes a config with environment variables expanded, includes and templates resolved,
+// and returns a ValidateError with unknown keys and invalid settings.
+func Parse(data []byte) (*Config, error) {
+	c, _, err := parse(data, "")
+
+	return c, err
+}
+
+// load is Load, which also returns the names of the files included by the config file.
+func load(name string) (*Config, []string, error) {
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to read")
+	}
+
+	c, includes, err := parse(buf, name)
+	if err != nil {
+		return c, includes, err
+	}
+
+	if err := secrets(c, filepath.Dir(name)); err != nil {
+		return c, includes, errors.Wrap(err, "failed to read secrets")
+	}
+
+	return c, includes, nil
+}
+
+func parse(data []byte, name string) (*Config, []string, error) {
+	v := validator{nodes: map[string]*yaml.Node{}, files: map[*yaml.Node]string{}}
+
+	dir := ""
+	seen := map[string]bool{}
+
+	if name != "" {
+		dir = filepath.Dir(name)
+		seen[filepath.Clean(name)] = true
+	}
+
+	node, err := v.load(data, "", dir, seen)
+	if err != nil {
+		return nil, v.includes, errors.Wrap(err, "failed to load")
+	}
+
+	v.templates(node)
+	v.walk(node, reflect.TypeOf(Config{}), "")
+
+	c := New()
+
+	if err := node.Decode(c); err != nil {
+		var e *yaml.TypeError
+		if !errors.As(err, &e) {
+			return nil, v.includes, errors.Wrap(err, "failed to decode")
+		}
+		for _, item := range e.Errors {
+			line := 0
+			_, _ = fmt.Sscanf(item, "line %d:", &line)
+			v.problems = append(v.problems, Problem{Line: line, Message: strings.TrimSpace(item[strings.Index(item, ":")+1:])})
+		}
+	}
+
+	v.validate(c)
+
+	if len(v.problems) != 0 {
+		sort.SliceStable(v.problems, func(i, j int) bool {
+			if v.problems[i].File != v.problems[j].File {
+				return v.problems[i].File < v.problems[j].File
+			}
+			return v.problems[i].Line < v.problems[j].Line
+		})
+		return c, v.includes, &ValidateError{Problems: v.problems}
+	}
+
+	return c, v.includes, nil
+}
+
+type validator struct {
+	nodes    map[string]*yaml.Node
+	files    map[*yaml.Node]string
+	includes []string
+	problems []Problem
+}
+
+// walk records the line of each key path and reports the keys unknown to the config type.
+func (v *validator) walk(node *yaml.Node, t reflect.Type, path string) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	v.nodes[path] = node
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := map[string]reflect.Type{}
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+			if name != "" && name != "-" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if ft, ok := fields[key]; ok {
+				v.walk(node.Content[i+1], ft, v.join(path, key))
+			} else if key != "<<" {
+				v.add(node.Content[i], "unknown key %q in %s", key, v.name(path))
+			}
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			v.walk(item, t.Elem(), path+"["+strconv.Itoa(i)+"]")
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v.walk(node.Content[i+1], t.Elem(), v.join(path, node.Content[i].Value))
+		}
+	default:
+	}
+}
+
+// nolint:funlen,gocyclo
+func (v *validator) validate(c *Config) {
+	if c.ApiVersion != ApiVersion {
+		v.at("apiVersion", "invalid apiVersion %q, expected %q", c.ApiVersion, ApiVersion)
+	}
+
+	if c.Kind != KindServer {
+		v.at("kind", "invalid kind %q, expected %q", c.Kind, KindServer)
+	}
+
+	if c.Spec.Flow.Timeout != "" {
+		if _, err := time.ParseDuration(c.Spec.Flow.Timeout); err != nil {
+			v.at("spec.flow.timeout", "invalid duration %q", c.Spec.Flow.Timeout)
+		}
+	}
+
+	if s := c.Spec.Flow.Override.Severity; s != "" {
+		v.severity("spec.flow.override.severity", s)
+	}
+
+	labels := map[string]bool{}
+
+	for i, item := range c.Spec.Review.Votes {
+		path := fmt.Sprintf("spec.review.vote[%d]", i)
+		if item.Label == "" {
+			v.at(path, "missing label")
+		}
+		labels[item.Label] = true
+		v.patterns(path+".branch", item.Branches)
+		if item.Policy.Severity != "" {
+			v.severity(path+".policy.severity", item.Policy.Severity)
+		}
+		for key := range item.Policy.Threshold {
+			v.severity(path+".policy.threshold."+key, key)
+		}
+		for key := range item.Policy.Score {
+			v.severity(path+".policy.score."+key, key)
+		}
+	}
+
+	for i, item := range c.Spec.Lints {
+		path := fmt.Sprintf("spec.lint[%d]", i)
+		if item.Name == "" {
+			v.at(path, "missing name")
+		}
+		if len(item.Command.Exec) == 0 {
+			if item.Host == "" {
+				v.at(path, "missing host of lint %q", item.Name)
+			}
+			if item.Port <= 0 || item.Port > 65535 {
+				v.at(path+".port", "invalid port %d of lint %q", item.Port, item.Name)
+			}
+		}
+		in := item.Filter.Include
+		if len(in.Extensions) == 0 && len(in.Files) == 0 && len(in.Paths) == 0 {
+			v.at(path+".filter", "empty filter of lint %q", item.Name)
+		}
+		v.patterns(path+".filter.include.repo", in.Repos)
+		v.patterns(path+".filter.include.branch", in.Branches)
+		v.patterns(path+".filter.exclude.repo", item.Filter.Exclude.Repos)
+		v.patterns(path+".filter.exclude.branch", item.Filter.Exclude.Branches)
+		for key, val := range item.Severity {
+			v.severity(path+".severity."+key, key)
+			v.severity(path+".severity."+key, val)
+		}
+		if item.Vote != "" && !labels[item.Vote] {
+			v.at(path+".vote", "vote %q of lint %q not found in spec.review.vote", item.Vote, item.Name)
+		}
+	}
+
+	if c.Spec.Review.Url == "" {
+		v.at("spec.review.url", "missing url")
+	}
+
+	if c.Spec.Review.Pass != "" && c.Spec.Review.PassFile != "" {
+		v.at("spec.review.passFile", "pass and passFile are exclusive")
+	}
+
+	if c.Spec.Review.Token != "" && c.Spec.Review.TokenFile != "" {
+		v.at("spec.review.tokenFile", "token and tokenFile are exclusive")
+	}
+
+	if c.Spec.Review.Cookie != "" && c.Spec.Review.CookieFile != "" {
+		v.at("spec.review.cookieFile", "cookie and cookieFile are exclusive")
+	}
+
+	if t := c.Spec.Review.Http.Timeout; t != "" {
+		if _, err := time.ParseDuration(t); err != nil {
+			v.at("spec.review.http.timeout", "invalid duration %q", t)
+		}
+	}
+
+	if p := c.Spec.Review.Http.Proxy; p != "" {
+		if u, err := url.Parse(p); err != nil || u.Scheme == "" || u.Host == "" {
+			v.at("spec.review.http.proxy", "invalid proxy %q", p)
+		}
+	}
+
+	if r := c.Spec.Review.Http.Retries; r < -1 {
+		v.at("spec.review.http.retries", "invalid retries %d", r)
+	}
+
+	if r := c.Spec.Review.Http.Rate; r < 0 {
+		v.at("spec.review.http.rate", "invalid rate %g", r)
+	}
+
+	if w := c.Spec.Review.Fetch.Workers; w < 0 {
+		v.at("spec.review.fetch.workers", "invalid workers %d", w)
+	}
+
+	if c.Spec.Review.Fetch.Mirror != "" && !c.Spec.Review.Fetch.Base {
+		v.at("spec.review.fetch.mirror", "mirror requires base")
+	}
+
+	if s := c.Spec.Review.Comment.Unresolved; s != "" {
+		v.severity("spec.review.comment.unresolved", s)
+	}
+
+	if !v.contains(fallbacks, c.Spec.Review.Comment.Fallback) {
+		v.at("spec.review.comment.fallback", "invalid fallback %q", c.Spec.Review.Comment.Fallback)
+	}
+}
+
+func (v *validator) patterns(path string, data []string) {
+	for i, item := range data {
+		if _, err := regexp.Compile("^(?:" + item + ")$"); err != nil {
+			v.at(path+"["+strconv.Itoa(i)+"]", "invalid pattern %q", item)
+		}
+	}
+}
+
+func (v *validator) severity(path, name string) {
+	if !v.contains(severities, name) {
+		v.at(path, "invalid severity %q, expected one of %s", name, strings.Join(severities, ", "))
+	}
+}
+
+func (v *validator) contains(data []string, name string) bool {
+	for _, item := range data {
+		if item == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// at adds a problem at the line of path, or of its closest parent.
+func (v *validator) at(path, msg string, args ...interface{}) {
+	for {
+		if node, ok := v.nodes[path]; ok {
+			v.add(node, msg, args...)
+			return
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+
+	v.add(v.nodes[""], msg, args...)
+}
+
+func (v *validator) add(node *yaml.Node, msg string, args ...interface{}) {
+	p := Problem{Message: fmt.Sprintf(msg, args...)}
+
+	if node != nil {
+		p.File = v.files[node]
+		p.Line = node.Line
+	}
+
+	v.problems = append(v.problems, p)
+}
+
+func (v *validator) join(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func (v *validator) name(path string) string {
+	if path == "" {
+		return "config"
+	}
+
+	return path
+}
@@ -0,0 +1,143 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	redacted = "******"
+)
+
+var (
+	variable = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+)
+
+// expand replaces ${NAME} and ${NAME:-default} in scalar values with environment variables,
+// $$ is replaced with $.
+func (v *validator) expand(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && strings.Contains(node.Value, "$") {
+		node.Value = variable.ReplaceAllStringFunc(node.Value, func(data string) string {
+			if data == "$$" {
+				return "$"
+			}
+			m := variable.FindStringSubmatch(data)
+			if val, ok := os.LookupEnv(m[1]); ok {
+				return val
+			}
+			if m[2] == "" {
+				v.add(node, "undefined environment variable %q", m[1])
+			}
+			return m[3]
+		})
+		// Resolve the tag of plain values again, e.g. ${PORT} as int
+		if node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			node.Tag = ""
+		}
+	}
+
+	for _, item := range node.Content {
+		v.expand(item)
+	}
+}
+
+// secrets reads passFile, tokenFile and cookieFile, and resolves http.caFile and fetch.mirror,
+// relative paths are relative to dir.
+func secrets(c *Config, dir string) error {
+	helper := func(name string) (string, error) {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(dir, name)
+		}
+		buf, err := os.ReadFile(name)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read")
+		}
+		return strings.TrimRight(string(buf), "\r\n"), nil
+	}
+
+	var err error
+
+	if c.Spec.Review.PassFile != "" {
+		if c.Spec.Review.Pass, err = helper(c.Spec.Review.PassFile); err != nil {
+			return errors.Wrap(err, "failed to read passFile")
+		}
+	}
+
+	if c.Spec.Review.TokenFile != "" {
+		if c.Spec.Review.Token, err = helper(c.Spec.Review.TokenFile); err != nil {
+			return errors.Wrap(err, "failed to read tokenFile")
+		}
+	}
+
+	if c.Spec.Review.CookieFile != "" {
+		if c.Spec.Review.Cookie, err = helper(c.Spec.Review.CookieFile); err != nil {
+			return errors.Wrap(err, "failed to read cookieFile")
+		}
+	}
+
+	if name := c.Spec.Review.Http.CaFile; name != "" && !filepath.IsAbs(name) {
+		c.Spec.Review.Http.CaFile = filepath.Join(dir, name)
+	}
+
+	if name := c.Spec.Review.Fetch.Mirror; name != "" && !filepath.IsAbs(name) {
+		c.Spec.Review.Fetch.Mirror = filepath.Join(dir, name)
+	}
+
+	return nil
+}
+
+// Redact returns a copy of config with secrets masked, to be logged.
+func (c *Config) Redact() *Config {
+	buf := *c
+
+	if buf.Spec.Review.Pass != "" {
+		buf.Spec.Review.Pass = redacted
+	}
+
+	if buf.Spec.Review.Token != "" {
+		buf.Spec.Review.Token = redacted
+	}
+
+	if buf.Spec.Review.Cookie != "" {
+		buf.Spec.Review.Cookie = redacted
+	}
+
+	return &buf
+}
+
+// String masks secrets when review is formatted.
+func (r Review) String() string {
+	if r.Pass != "" {
+		r.Pass = redacted
+	}
+
+	if r.Token != "" {
+		r.Token = redacted
+	}
+
+	if r.Cookie != "" {
+		r.Cookie = redacted
+	}
+
+	type review Review
+
+	return fmt.Sprintf("%+v", review(r))
+}
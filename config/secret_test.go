@@ -0,0 +1,109 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	secretConfig = `apiVersion: v1
+kind: server
+spec:
+  lint:
+    - name: lintshell
+      host: ${LINT_HOST:-127.0.0.1}
+      port: ${LINT_PORT}
+      filter:
+        include:
+          extension:
+            - .sh
+          repo:
+            - kernel/msm-.*$$
+  review:
+    url: http://${GERRIT_HOST}:8080
+    user: user
+    passFile: pass.txt
+    cookieFile: cookie.txt
+    http:
+      caFile: ca.pem
+`
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("LINT_PORT", "9090")
+	t.Setenv("GERRIT_HOST", "gerrit")
+
+	c, err := Parse([]byte(secretConfig))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "127.0.0.1", c.Spec.Lints[0].Host)
+	assert.Equal(t, 9090, c.Spec.Lints[0].Port)
+	assert.Equal(t, "kernel/msm-.*$", c.Spec.Lints[0].Filter.Include.Repos[0])
+	assert.Equal(t, "http://gerrit:8080", c.Spec.Review.Url)
+
+	t.Setenv("LINT_PORT", "")
+	_ = os.Unsetenv("LINT_PORT")
+
+	_, err = Parse([]byte(secretConfig))
+
+	var e *ValidateError
+
+	assert.Equal(t, true, errors.As(err, &e))
+	assert.Equal(t, Problem{Line: 7, Message: `undefined environment variable "LINT_PORT"`}, e.Problems[0])
+}
+
+func TestSecrets(t *testing.T) {
+	t.Setenv("LINT_PORT", "9090")
+	t.Setenv("GERRIT_HOST", "gerrit")
+
+	dir := t.TempDir()
+	name := filepath.Join(dir, "config.yml")
+
+	err := os.WriteFile(name, []byte(secretConfig), 0600)
+	assert.Equal(t, nil, err)
+
+	_, err = Load(name)
+	assert.NotEqual(t, nil, err)
+
+	err = os.WriteFile(filepath.Join(dir, "pass.txt"), []byte("secret\n"), 0600)
+	assert.Equal(t, nil, err)
+
+	err = os.WriteFile(filepath.Join(dir, "cookie.txt"), []byte("o=cookie\n"), 0600)
+	assert.Equal(t, nil, err)
+
+	c, err := Load(name)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "secret", c.Spec.Review.Pass)
+	assert.Equal(t, "o=cookie", c.Spec.Review.Cookie)
+	assert.Equal(t, filepath.Join(dir, "ca.pem"), c.Spec.Review.Http.CaFile)
+
+	c.Spec.Review.Token = "token"
+
+	r := c.Redact()
+	assert.Equal(t, redacted, r.Spec.Review.Pass)
+	assert.Equal(t, redacted, r.Spec.Review.Token)
+	assert.Equal(t, redacted, r.Spec.Review.Cookie)
+	assert.Equal(t, "secret", c.Spec.Review.Pass)
+
+	buf := fmt.Sprintf("%v", c)
+	assert.Equal(t, false, strings.Contains(buf, "secret"))
+	assert.Equal(t, false, strings.Contains(buf, "token"))
+	assert.Equal(t, false, strings.Contains(buf, "o=cookie"))
+}
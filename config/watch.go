@@ -0,0 +1,132 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Holder holds a config file loaded by Load, which is reloaded atomically by Reload and Watch.
+// Flows take a snapshot with Get, so that reloads don't affect flows in progress.
+type Holder struct {
+	name     string
+	cfg      atomic.Pointer[Config]
+	mu       sync.Mutex
+	mtime    time.Time
+	includes []string
+}
+
+func NewHolder(name string) (*Holder, error) {
+	h := &Holder{name: name}
+
+	if err := h.Reload(); err != nil {
+		return nil, errors.Wrap(err, "failed to reload")
+	}
+
+	return h, nil
+}
+
+// Get returns the current config snapshot, which must not be modified.
+func (h *Holder) Get() *Config {
+	return h.cfg.Load()
+}
+
+// Reload loads the config file again, the current config is kept if the file is invalid.
+func (h *Holder) Reload() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.mtime = h.modified()
+
+	c, includes, err := load(h.name)
+	if err != nil {
+		return errors.Wrap(err, "failed to load")
+	}
+
+	h.cfg.Store(c)
+	h.includes = includes
+
+	return nil
+}
+
+// Watch reloads the config file on SIGHUP, and when the file, its includes or its secrets are modified,
+// until ctx is done. Rejected configs are logged.
+func (h *Holder) Watch(ctx context.Context, interval time.Duration) {
+	helper := func(reason string) {
+		if err := h.Reload(); err != nil {
+			log.Printf("config reload rejected (%s): %v\n", reason, err)
+			return
+		}
+		log.Printf("config reloaded (%s)\n", reason)
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+
+	defer signal.Stop(ch)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			helper("signal")
+		case <-t.C:
+			h.mu.Lock()
+			changed := h.modified().After(h.mtime)
+			h.mu.Unlock()
+			if changed {
+				helper("modified")
+			}
+		}
+	}
+}
+
+// modified returns the latest modification time of the config file, its included files and its secret files.
+func (h *Holder) modified() time.Time {
+	var buf time.Time
+
+	names := append([]string{h.name}, h.includes...)
+
+	if c := h.cfg.Load(); c != nil {
+		for _, item := range []string{c.Spec.Review.PassFile, c.Spec.Review.TokenFile, c.Spec.Review.CookieFile} {
+			if item != "" && !filepath.IsAbs(item) {
+				item = filepath.Join(filepath.Dir(h.name), item)
+			}
+			if item != "" {
+				names = append(names, item)
+			}
+		}
+	}
+
+	for _, item := range names {
+		if fi, err := os.Stat(item); err == nil && fi.ModTime().After(buf) {
+			buf = fi.ModTime()
+		}
+	}
+
+	return buf
+}
@@ -0,0 +1,107 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHolder(t *testing.T) {
+	buf, err := os.ReadFile("../tests/config.yml")
+	assert.Equal(t, nil, err)
+
+	name := filepath.Join(t.TempDir(), "config.yml")
+
+	err = os.WriteFile(name, buf, 0600)
+	assert.Equal(t, nil, err)
+
+	h, err := NewHolder(name)
+	assert.Equal(t, nil, err)
+
+	c := h.Get()
+	assert.Equal(t, "120s", c.Spec.Flow.Timeout)
+
+	err = os.WriteFile(name, []byte("kind: invalid\n"), 0600)
+	assert.Equal(t, nil, err)
+
+	err = h.Reload()
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, c, h.Get())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		h.Watch(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	err = os.WriteFile(name, []byte(strings.Replace(string(buf), "timeout: 120s", "timeout: 60s", 1)), 0600)
+	assert.Equal(t, nil, err)
+
+	future := time.Now().Add(time.Second)
+	err = os.Chtimes(name, future, future)
+	assert.Equal(t, nil, err)
+
+	assert.Eventually(t, func() bool {
+		return h.Get().Spec.Flow.Timeout == "60s"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "120s", c.Spec.Flow.Timeout)
+
+	cancel()
+	<-done
+}
+
+func TestHolderInclude(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "base.yml"), []byte(includeBase), 0600)
+	assert.Equal(t, nil, err)
+
+	err = os.WriteFile(filepath.Join(dir, "config.yml"), []byte(includeConfig), 0600)
+	assert.Equal(t, nil, err)
+
+	h, err := NewHolder(filepath.Join(dir, "config.yml"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 9090, h.Get().Spec.Lints[0].Port)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		h.Watch(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	err = os.WriteFile(filepath.Join(dir, "base.yml"), []byte(strings.Replace(includeBase, "port: 9090", "port: 9092", 1)), 0600)
+	assert.Equal(t, nil, err)
+
+	future := time.Now().Add(time.Second)
+	err = os.Chtimes(filepath.Join(dir, "base.yml"), future, future)
+	assert.Equal(t, nil, err)
+
+	assert.Eventually(t, func() bool {
+		return h.Get().Spec.Lints[0].Port == 9092
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
@@ -14,12 +14,21 @@ package flow
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
 
 	"github.com/devops-lintflow/lintflow/config"
 	"github.com/devops-lintflow/lintflow/format"
@@ -27,14 +36,51 @@ import (
 	"github.com/devops-lintflow/lintflow/review"
 )
 
+const (
+	ExitCodeError = "error"
+	ExitCodeInfo  = "info"
+	ExitCodeNone  = "none"
+	ExitCodeWarn  = "warn"
+)
+
+const (
+	OutputCheckstyle  = "checkstyle"
+	OutputCodeclimate = "codeclimate"
+	OutputJson        = "json"
+	OutputJunit       = "junit"
+	OutputPretty      = "pretty"
+	OutputText        = "text"
+)
+
+var (
+	ErrFindings = errors.New("findings reported")
+)
+
+var (
+	binaries = []string{".a", ".apk", ".bin", ".img", ".jar", ".so", ".zip"}
+	patterns sync.Map
+)
+
+var (
+	exitCodes = map[string]string{
+		ExitCodeError: format.TypeError,
+		ExitCodeInfo:  format.TypeInfo,
+		ExitCodeWarn:  format.TypeWarn,
+	}
+)
+
 type Flow interface {
 	Run(context.Context, string) error
 }
 
 type Config struct {
-	Config config.Config
-	Lint   lint.Lint
-	Review review.Review
+	Config       config.Config
+	Lint         lint.Lint
+	Review       review.Review
+	ExitCode     string
+	OutputFile   string
+	OutputFormat string
+	SarifFile    string
 }
 
 type flow struct {
@@ -57,7 +103,16 @@ func (f *flow) Run(ctx context.Context, commit string) error {
 
 	root := filepath.Join(d, "gerrit-"+t.Format("2006-01-02"))
 
-	dir, repo, files, meta, patch, err := f.cfg.Review.Fetch(root, commit)
+	fetch := func(repo, branch, file string) bool {
+		for i := range f.cfg.Config.Spec.Lints {
+			if f.matchLint(&f.cfg.Config.Spec.Lints[i], repo, branch, file) {
+				return true
+			}
+		}
+		return false
+	}
+
+	dir, repo, files, meta, patch, err := f.cfg.Review.Fetch(ctx, root, commit, fetch)
 
 	defer func() {
 		_ = f.cfg.Review.Clean(root)
@@ -67,11 +122,50 @@ func (f *flow) Run(ctx context.Context, commit string) error {
 		return errors.Wrap(err, "failed to clean reivew")
 	}
 
-	buf, err := f.cfg.Lint.Run(ctx, dir, repo, files, meta, patch, f.matchFilter)
+	m, err := f.meta(dir, meta)
+	if err != nil {
+		return errors.Wrap(err, "failed to read meta")
+	}
+
+	branch, _ := m["branch"].(string)
+
+	overrides := f.override(dir, m)
+
+	match := func(lint *config.Lint, repo, file string) bool {
+		filter := lint.Filter
+		if o, ok := overrides[lint.Name]; ok {
+			if o.Disable {
+				return false
+			}
+			filter.Exclude = f.mergeMatch(filter.Exclude, o.Filter.Exclude)
+		}
+		l := *lint
+		l.Filter = filter
+		return f.matchLint(&l, repo, branch, file)
+	}
+
+	buf, timings, err := f.cfg.Lint.Run(ctx, dir, repo, files, meta, patch, match)
 	if err != nil {
 		return errors.Wrap(err, "failed to run lint")
 	}
 
+	f.severity(buf, overrides)
+
+	if f.cfg.SarifFile != "" {
+		if err := f.write(f.cfg.SarifFile, buf, format.WriteSarif); err != nil {
+			return errors.Wrap(err, "failed to write sarif")
+		}
+	}
+
+	if err := f.output(repo, buf); err != nil {
+		return errors.Wrap(err, "failed to write output")
+	}
+
+	report, err := f.report(dir, meta, patch, buf, timings)
+	if err != nil {
+		return errors.Wrap(err, "failed to write report")
+	}
+
 	if buf == nil || len(buf) == 0 {
 		return nil
 	}
@@ -79,49 +173,299 @@ func (f *flow) Run(ctx context.Context, commit string) error {
 	labels := f.buildLabel(buf)
 
 	for label, reports := range labels {
-		fmt.Printf("   repo: %s\n", repo)
-		fmt.Printf("  label: %s\n", label)
-		for _, item := range reports {
-			fmt.Printf("   file: %s\n", item.File)
-			fmt.Printf("   line: %d\n", item.Line)
-			fmt.Printf("   type: %s\n", item.Type)
-			fmt.Printf("details: %s\n", item.Details)
-		}
-		if vote := f.buildVote(label); vote.Label != "" {
-			if err := f.cfg.Review.Vote(commit, reports, vote); err != nil {
+		if vote := f.buildVote(label, branch); vote.Label != "" {
+			if report != "" {
+				vote.Message += "\n\nReport: " + report
+			}
+			if err := f.cfg.Review.Vote(ctx, commit, reports, vote); err != nil {
 				return errors.Wrap(err, "failed to vote reivew")
 			}
 		}
 	}
 
-	return nil
+	return f.exitCode(buf)
 }
 
-func (f *flow) matchFilter(filter *config.Filter, repo, file string) bool {
-	matchExtension := func(filter *config.Filter, data string) bool {
-		for _, val := range filter.Include.Extensions {
-			if val == filepath.Ext(data) {
-				return true
+func (f *flow) output(repo string, data map[string][]format.Report) error {
+	pretty := func(w io.Writer, data map[string][]format.Report) error {
+		for label, reports := range f.buildLabel(data) {
+			_, _ = fmt.Fprintf(w, "   repo: %s\n", repo)
+			_, _ = fmt.Fprintf(w, "  label: %s\n", label)
+			for _, item := range reports {
+				_, _ = fmt.Fprintf(w, "   file: %s\n", item.File)
+				_, _ = fmt.Fprintf(w, "   line: %d\n", item.Line)
+				_, _ = fmt.Fprintf(w, "   type: %s\n", item.Type)
+				_, _ = fmt.Fprintf(w, "details: %s\n", item.Details)
+			}
+		}
+		return nil
+	}
+
+	var writer func(io.Writer, map[string][]format.Report) error
+
+	switch f.cfg.OutputFormat {
+	case OutputCheckstyle:
+		writer = format.WriteCheckstyle
+	case OutputCodeclimate:
+		writer = format.WriteCodeclimate
+	case OutputJson:
+		writer = format.WriteJson
+	case OutputJunit:
+		writer = format.WriteJunit
+	case OutputText:
+		writer = format.WriteText
+	default:
+		writer = pretty
+	}
+
+	if f.cfg.OutputFile == "" {
+		return writer(os.Stdout, data)
+	}
+
+	return f.write(f.cfg.OutputFile, data, writer)
+}
+
+// override returns the lints of the repository config fetched from the target branch, restricted
+// to what spec.flow.override permits. Invalid repository configs are logged and ignored.
+func (f *flow) override(dir string, meta map[string]interface{}) map[string]config.Lint {
+	o := f.cfg.Config.Spec.Flow.Override
+
+	name, _ := meta["config"].(string)
+	if o.File == "" || name == "" {
+		return nil
+	}
+
+	buf, err := f.read(dir, name)
+	if err != nil {
+		log.Printf("override: %v\n", err)
+		return nil
+	}
+
+	var c config.Config
+
+	if err := yaml.Unmarshal(buf, &c); err != nil {
+		log.Printf("override: %v\n", err)
+		return nil
+	}
+
+	lints := map[string]config.Lint{}
+
+	for _, item := range c.Spec.Lints {
+		l := config.Lint{Name: item.Name}
+		if o.Disable {
+			l.Disable = item.Disable
+		}
+		if o.Exclude {
+			l.Filter.Exclude = item.Filter.Exclude
+		}
+		if o.Severity != "" && len(item.Severity) != 0 {
+			l.Severity = map[string]string{}
+			for key, val := range item.Severity {
+				if format.Severity(val) < format.Severity(o.Severity) && format.Severity(val) < format.Severity(key) {
+					val = o.Severity
+					if format.Severity(key) < format.Severity(o.Severity) {
+						val = key
+					}
+				}
+				l.Severity[key] = val
 			}
 		}
+		lints[item.Name] = l
+	}
+
+	return lints
+}
+
+// mergeMatch appends the patterns of other to data.
+func (f *flow) mergeMatch(data, other config.Match) config.Match {
+	data.Extensions = append(append([]string{}, data.Extensions...), other.Extensions...)
+	data.Files = append(append([]string{}, data.Files...), other.Files...)
+	data.Paths = append(append([]string{}, data.Paths...), other.Paths...)
+	data.Repos = append(append([]string{}, data.Repos...), other.Repos...)
+	data.Branches = append(append([]string{}, data.Branches...), other.Branches...)
+
+	return data
+}
+
+// severity maps the report types of lints with spec.lint severity, overridden by the repository config.
+func (f *flow) severity(data map[string][]format.Report, overrides map[string]config.Lint) {
+	done := map[string]bool{}
+
+	for _, item := range f.cfg.Config.Spec.Lints {
+		if done[item.Name] {
+			continue
+		}
+		done[item.Name] = true
+		severity := map[string]string{}
+		for key, val := range item.Severity {
+			severity[key] = val
+		}
+		for key, val := range overrides[item.Name].Severity {
+			severity[key] = val
+		}
+		for i := range data[item.Name] {
+			if val, ok := severity[data[item.Name][i].Type]; ok {
+				data[item.Name][i].Type = val
+			}
+		}
+	}
+}
+
+// read returns the decoded content of a file written by Fetch.
+func (f *flow) read(dir, name string) ([]byte, error) {
+	buf, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	dec := make([]byte, base64.StdEncoding.DecodedLen(len(buf)))
+
+	n, err := base64.StdEncoding.Decode(dec, buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode")
+	}
+
+	return dec[:n], nil
+}
+
+func (f *flow) meta(dir, name string) (map[string]interface{}, error) {
+	buf, err := f.read(dir, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	m := map[string]interface{}{}
+
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return m, nil
+}
+
+// report writes the HTML report of the run into the report dir, and returns its url if any.
+func (f *flow) report(dir, meta, patch string, data map[string][]format.Report, timings map[string]time.Duration) (string, error) {
+	r := f.cfg.Config.Spec.Flow.Report
+	if r.Dir == "" {
+		return "", nil
+	}
+
+	m, err := f.meta(dir, meta)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read meta")
+	}
+
+	p, err := f.read(dir, patch)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read patch")
+	}
+
+	h := &format.Html{
+		Meta:    m,
+		Patch:   p,
+		Reports: data,
+		Timings: timings,
+	}
+
+	name := strings.TrimSuffix(meta, filepath.Ext(meta)) + ".html"
+
+	_ = os.MkdirAll(r.Dir, os.ModePerm)
+
+	fi, err := os.Create(filepath.Join(r.Dir, name))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create")
+	}
+
+	defer func() {
+		_ = fi.Close()
+	}()
+
+	if err := format.WriteHtml(fi, h); err != nil {
+		return "", errors.Wrap(err, "failed to write")
+	}
+
+	if r.Url == "" {
+		return "", nil
+	}
+
+	return strings.TrimSuffix(r.Url, "/") + "/" + name, nil
+}
+
+// exitCode returns ErrFindings if a report has a severity of at least the exit code policy.
+func (f *flow) exitCode(data map[string][]format.Report) error {
+	severity, ok := exitCodes[f.cfg.ExitCode]
+	if !ok {
+		return nil
+	}
+
+	for _, reports := range data {
+		for _, item := range reports {
+			if format.Severity(item.Type) >= format.Severity(severity) {
+				return ErrFindings
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *flow) write(name string, data map[string][]format.Report,
+	writer func(io.Writer, map[string][]format.Report) error) error {
+	fi, err := os.Create(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to create")
+	}
+
+	defer func() {
+		_ = fi.Close()
+	}()
+
+	if err := writer(fi, data); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
+
+// matchLint matches a file with the filter of an enabled lint, binaries are matched only if
+// their extension or name is included explicitly.
+func (f *flow) matchLint(lint *config.Lint, repo, branch, file string) bool {
+	if lint.Disable || !f.matchFilter(&lint.Filter, repo, branch, file) {
 		return false
 	}
 
-	matchFile := func(filter *config.Filter, data string) bool {
-		for _, val := range filter.Include.Files {
+	if !f.contains(binaries, filepath.Ext(file)) {
+		return true
+	}
+
+	return f.contains(lint.Filter.Include.Extensions, filepath.Ext(file)) || f.contains(lint.Filter.Include.Files, filepath.Base(file))
+}
+
+// matchFilter matches a file of repo on branch, repos and branches are anchored regular expressions
+// and paths are glob patterns where ** matches any number of directories.
+func (f *flow) matchFilter(filter *config.Filter, repo, branch, file string) bool {
+	matchFile := func(extensions, files, paths []string, data string) bool {
+		for _, val := range extensions {
+			if val == filepath.Ext(data) {
+				return true
+			}
+		}
+		for _, val := range files {
 			if val == filepath.Base(data) {
 				return true
 			}
 		}
+		for _, val := range paths {
+			if matchGlob(val, data) {
+				return true
+			}
+		}
 		return false
 	}
 
-	matchRepo := func(filter *config.Filter, data string) bool {
-		if len(filter.Include.Repos) == 0 {
-			return true
-		}
-		for _, val := range filter.Include.Repos {
-			if val == data {
+	matchName := func(patterns []string, data string) bool {
+		for _, val := range patterns {
+			if matchRegexp(val, data) {
 				return true
 			}
 		}
@@ -132,17 +476,98 @@ func (f *flow) matchFilter(filter *config.Filter, repo, file string) bool {
 		return false
 	}
 
-	if repo != "" && !matchRepo(filter, repo) {
+	if repo != "" && len(filter.Include.Repos) != 0 && !matchName(filter.Include.Repos, repo) {
 		return false
 	}
 
-	if !matchExtension(filter, file) && !matchFile(filter, file) {
+	if branch != "" && len(filter.Include.Branches) != 0 && !matchName(filter.Include.Branches, branch) {
+		return false
+	}
+
+	if repo != "" && matchName(filter.Exclude.Repos, repo) {
+		return false
+	}
+
+	if branch != "" && matchName(filter.Exclude.Branches, branch) {
+		return false
+	}
+
+	if !matchFile(filter.Include.Extensions, filter.Include.Files, filter.Include.Paths, file) {
+		return false
+	}
+
+	if matchFile(filter.Exclude.Extensions, filter.Exclude.Files, filter.Exclude.Paths, file) {
 		return false
 	}
 
 	return true
 }
 
+func (f *flow) contains(data []string, name string) bool {
+	for _, item := range data {
+		if item == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchGlob matches name with pattern segment by segment, a pattern without separator matches the base name.
+func matchGlob(pattern, name string) bool {
+	var helper func(p, n []string) bool
+
+	helper = func(p, n []string) bool {
+		for len(p) != 0 {
+			if p[0] == "**" {
+				if len(p) == 1 {
+					return true
+				}
+				for i := range len(n) + 1 {
+					if helper(p[1:], n[i:]) {
+						return true
+					}
+				}
+				return false
+			}
+			if len(n) == 0 {
+				return false
+			}
+			if ok, _ := path.Match(p[0], n[0]); !ok {
+				return false
+			}
+			p, n = p[1:], n[1:]
+		}
+		return len(n) == 0
+	}
+
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+
+	return helper(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), strings.Split(name, "/"))
+}
+
+// matchRegexp matches the whole name with pattern, invalid patterns match nothing.
+func matchRegexp(pattern, name string) bool {
+	val, ok := patterns.Load(pattern)
+	if !ok {
+		r, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			r = nil
+		}
+		val, _ = patterns.LoadOrStore(pattern, r)
+	}
+
+	r := val.(*regexp.Regexp)
+	if r == nil {
+		return false
+	}
+
+	return r.MatchString(name)
+}
+
 func (f *flow) buildLabel(data map[string][]format.Report) map[string][]format.Report {
 	helper := func(name string) string {
 		var buf string
@@ -171,14 +596,27 @@ func (f *flow) buildLabel(data map[string][]format.Report) map[string][]format.R
 	return buf
 }
 
-func (f *flow) buildVote(label string) config.Vote {
+// buildVote returns the first vote of label whose branches match branch, votes without branches match all.
+func (f *flow) buildVote(label, branch string) config.Vote {
+	helper := func(vote *config.Vote) bool {
+		if len(vote.Branches) == 0 || branch == "" {
+			return true
+		}
+		for _, val := range vote.Branches {
+			if matchRegexp(val, branch) {
+				return true
+			}
+		}
+		return false
+	}
+
 	var buf config.Vote
 
 	votes := f.cfg.Config.Spec.Review.Votes
 
-	for _, item := range votes {
-		if item.Label == label {
-			buf = item
+	for i := range votes {
+		if votes[i].Label == label && helper(&votes[i]) {
+			buf = votes[i]
 			break
 		}
 	}
@@ -13,9 +13,12 @@
 package flow
 
 import (
+	"encoding/base64"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -56,7 +59,7 @@ func initConfig(name string) (*config.Config, error) {
 // nolint: goconst
 func TestMatchFilter(t *testing.T) {
 	filter := config.Filter{
-		Include: config.Include{
+		Include: config.Match{
 			Extensions: []string{".java", ".xml"},
 			Files:      []string{"message"},
 			Repos:      []string{""},
@@ -65,46 +68,116 @@ func TestMatchFilter(t *testing.T) {
 
 	f := flow{}
 
-	ret := f.matchFilter(&filter, "", ".ext")
+	ret := f.matchFilter(&filter, "", "", ".ext")
 	assert.Equal(t, false, ret)
 
-	ret = f.matchFilter(&filter, "", "foo.ext")
+	ret = f.matchFilter(&filter, "", "", "foo.ext")
 	assert.Equal(t, false, ret)
 
-	ret = f.matchFilter(&filter, "", ".java")
+	ret = f.matchFilter(&filter, "", "", ".java")
 	assert.Equal(t, true, ret)
 
-	ret = f.matchFilter(nil, "", ".java")
+	ret = f.matchFilter(nil, "", "", ".java")
 	assert.Equal(t, false, ret)
 
-	ret = f.matchFilter(&filter, "", "foo.java")
+	ret = f.matchFilter(&filter, "", "", "foo.java")
 	assert.Equal(t, true, ret)
 
-	ret = f.matchFilter(nil, "", "foo.java")
+	ret = f.matchFilter(nil, "", "", "foo.java")
 	assert.Equal(t, false, ret)
 
-	ret = f.matchFilter(&filter, "", "message")
+	ret = f.matchFilter(&filter, "", "", "message")
 	assert.Equal(t, true, ret)
 
-	ret = f.matchFilter(nil, "", "message")
+	ret = f.matchFilter(nil, "", "", "message")
 	assert.Equal(t, false, ret)
 
 	filter.Include.Repos = []string{"alpha", "beta"}
 
-	ret = f.matchFilter(&filter, "", "message")
+	ret = f.matchFilter(&filter, "", "", "message")
 	assert.Equal(t, true, ret)
 
-	ret = f.matchFilter(nil, "", "message")
+	ret = f.matchFilter(nil, "", "", "message")
 	assert.Equal(t, false, ret)
 
-	ret = f.matchFilter(&filter, "foo", "message")
+	ret = f.matchFilter(&filter, "foo", "", "message")
 	assert.Equal(t, false, ret)
 
-	ret = f.matchFilter(&filter, "alpha", "message")
+	ret = f.matchFilter(&filter, "alpha", "", "message")
 	assert.Equal(t, true, ret)
 
-	ret = f.matchFilter(nil, "alpha", "message")
+	ret = f.matchFilter(nil, "alpha", "", "message")
 	assert.Equal(t, false, ret)
+
+	filter.Include.Repos = []string{"kernel/msm-.*"}
+	filter.Include.Branches = []string{"main", "release/.*"}
+
+	ret = f.matchFilter(&filter, "kernel/msm-5.4", "main", "foo.java")
+	assert.Equal(t, true, ret)
+
+	ret = f.matchFilter(&filter, "kernel/common", "main", "foo.java")
+	assert.Equal(t, false, ret)
+
+	ret = f.matchFilter(&filter, "kernel/msm-5.4", "dev", "foo.java")
+	assert.Equal(t, false, ret)
+
+	ret = f.matchFilter(&filter, "kernel/msm-5.4", "release/1.0", "foo.java")
+	assert.Equal(t, true, ret)
+
+	filter.Include.Paths = []string{"src/**/*.go"}
+	filter.Exclude = config.Match{
+		Paths:    []string{"vendor/**", "*_test.go"},
+		Branches: []string{"release/.*"},
+	}
+
+	ret = f.matchFilter(&filter, "", "", "src/foo/bar/main.go")
+	assert.Equal(t, true, ret)
+
+	ret = f.matchFilter(&filter, "", "", "src/main_test.go")
+	assert.Equal(t, false, ret)
+
+	ret = f.matchFilter(&filter, "", "", "vendor/foo/bar.java")
+	assert.Equal(t, false, ret)
+
+	ret = f.matchFilter(&filter, "", "release/1.0", "foo.java")
+	assert.Equal(t, false, ret)
+}
+
+func TestMatchLint(t *testing.T) {
+	lint := config.Lint{
+		Filter: config.Filter{
+			Include: config.Match{
+				Extensions: []string{".so"},
+				Paths:      []string{"prebuilts/**"},
+			},
+		},
+	}
+
+	f := flow{}
+
+	assert.Equal(t, true, f.matchLint(&lint, "", "", "lib/libfoo.so"))
+	assert.Equal(t, false, f.matchLint(&lint, "", "", "prebuilts/app.apk"))
+	assert.Equal(t, true, f.matchLint(&lint, "", "", "prebuilts/Android.bp"))
+
+	lint.Disable = true
+	assert.Equal(t, false, f.matchLint(&lint, "", "", "lib/libfoo.so"))
+}
+
+func TestMatchGlob(t *testing.T) {
+	assert.Equal(t, true, matchGlob("vendor/**", "vendor/foo/bar.go"))
+	assert.Equal(t, true, matchGlob("**/*_test.go", "foo_test.go"))
+	assert.Equal(t, true, matchGlob("**/*_test.go", "foo/bar/foo_test.go"))
+	assert.Equal(t, true, matchGlob("*.go", "foo/bar.go"))
+	assert.Equal(t, true, matchGlob("/foo/*.go", "foo/bar.go"))
+	assert.Equal(t, false, matchGlob("foo/*.go", "foo/bar/baz.go"))
+	assert.Equal(t, false, matchGlob("vendor/**", "third_party/vendor/foo.go"))
+}
+
+func TestMatchRegexp(t *testing.T) {
+	assert.Equal(t, true, matchRegexp("kernel/msm-.*", "kernel/msm-4.19"))
+	assert.Equal(t, false, matchRegexp("kernel/msm-.*", "vendor/kernel/msm-4.19"))
+	assert.Equal(t, false, matchRegexp("kernel", "kernel/common"))
+	assert.Equal(t, false, matchRegexp("(", "("))
 }
 
 func TestBuildLabel(t *testing.T) {
@@ -153,6 +226,189 @@ func TestBuildVote(t *testing.T) {
 		cfg: cfg,
 	}
 
-	ret := f.buildVote("Lint-Verified")
+	ret := f.buildVote("Lint-Verified", "")
 	assert.Equal(t, "Lint-Verified", ret.Label)
+
+	cfg.Config.Spec.Review.Votes = []config.Vote{
+		{Label: "Lint-Verified", Disapproval: "-2", Branches: []string{"release/.*"}},
+		{Label: "Lint-Verified", Disapproval: "-1"},
+	}
+
+	ret = f.buildVote("Lint-Verified", "release/1.0")
+	assert.Equal(t, "-2", ret.Disapproval)
+
+	ret = f.buildVote("Lint-Verified", "main")
+	assert.Equal(t, "-1", ret.Disapproval)
+
+	ret = f.buildVote("Verified", "main")
+	assert.Equal(t, "", ret.Label)
+}
+
+func TestOutput(t *testing.T) {
+	c, err := initConfig("../tests/config.yml")
+	assert.Equal(t, nil, err)
+
+	cfg := DefaultConfig()
+	cfg.Config = *c
+	cfg.OutputFile = filepath.Join(t.TempDir(), "output.txt")
+	cfg.OutputFormat = OutputText
+
+	f := flow{
+		cfg: cfg,
+	}
+
+	buf := map[string][]format.Report{
+		"lintshell": {
+			{
+				File:    "lintshell/test.sh",
+				Line:    1,
+				Type:    format.TypeError,
+				Details: "Disapproved",
+			},
+		},
+	}
+
+	err = f.output("lintshell", buf)
+	assert.Equal(t, nil, err)
+
+	ret, err := os.ReadFile(cfg.OutputFile)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "lintshell:lintshell/test.sh:1:Error:Disapproved\n", string(ret))
+}
+
+func TestExitCode(t *testing.T) {
+	f := flow{
+		cfg: DefaultConfig(),
+	}
+
+	buf := map[string][]format.Report{
+		"lintshell": {
+			{
+				File:    "lintshell/test.sh",
+				Line:    1,
+				Type:    format.TypeWarn,
+				Details: "Disapproved",
+			},
+		},
+	}
+
+	assert.Equal(t, nil, f.exitCode(buf))
+
+	f.cfg.ExitCode = ExitCodeNone
+	assert.Equal(t, nil, f.exitCode(buf))
+
+	f.cfg.ExitCode = ExitCodeError
+	assert.Equal(t, nil, f.exitCode(buf))
+
+	f.cfg.ExitCode = ExitCodeWarn
+	assert.Equal(t, ErrFindings, f.exitCode(buf))
+
+	f.cfg.ExitCode = ExitCodeInfo
+	assert.Equal(t, ErrFindings, f.exitCode(buf))
+}
+
+func TestReport(t *testing.T) {
+	c, err := initConfig("../tests/config.yml")
+	assert.Equal(t, nil, err)
+
+	cfg := DefaultConfig()
+	cfg.Config = *c
+
+	f := flow{
+		cfg: cfg,
+	}
+
+	buf := map[string][]format.Report{
+		"lintshell": {
+			{
+				File:    "lintshell/test.sh",
+				Line:    3,
+				Type:    format.TypeError,
+				Details: "Disapproved",
+			},
+		},
+	}
+
+	timings := map[string]time.Duration{
+		"lintshell": time.Second,
+	}
+
+	ret, err := f.report("../tests/project", "42-a4bc7bd.meta", "42-a4bc7bd.patch", buf, timings)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "", ret)
+
+	cfg.Config.Spec.Flow.Report.Dir = t.TempDir()
+	cfg.Config.Spec.Flow.Report.Url = "http://127.0.0.1/reports/"
+
+	ret, err = f.report("../tests/project", "42-a4bc7bd.meta", "42-a4bc7bd.patch", buf, timings)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "http://127.0.0.1/reports/42-a4bc7bd.html", ret)
+
+	_, err = os.Stat(filepath.Join(cfg.Config.Spec.Flow.Report.Dir, "42-a4bc7bd.html"))
+	assert.Equal(t, nil, err)
+}
+
+func TestOverride(t *testing.T) {
+	c, err := initConfig("../tests/config.yml")
+	assert.Equal(t, nil, err)
+
+	cfg := DefaultConfig()
+	cfg.Config = *c
+
+	f := flow{
+		cfg: cfg,
+	}
+
+	data := `apiVersion: v1
+kind: repo
+spec:
+  lint:
+    - name: lintai
+      disable: true
+    - name: lintshell
+      filter:
+        include:
+          extension:
+            - .txt
+        exclude:
+          path:
+            - third_party/**
+      severity:
+        Error: Info
+        Info: Error
+`
+
+	dir := t.TempDir()
+	name := "42-a4bc7bd.yml"
+
+	err = os.WriteFile(filepath.Join(dir, name), []byte(base64.StdEncoding.EncodeToString([]byte(data))), 0600)
+	assert.Equal(t, nil, err)
+
+	ret := f.override(dir, map[string]interface{}{"config": name})
+	assert.Equal(t, true, ret["lintai"].Disable)
+	assert.Equal(t, []string{"third_party/**"}, ret["lintshell"].Filter.Exclude.Paths)
+	assert.Equal(t, 0, len(ret["lintshell"].Filter.Include.Extensions))
+	assert.Equal(t, format.TypeWarn, ret["lintshell"].Severity[format.TypeError])
+	assert.Equal(t, format.TypeError, ret["lintshell"].Severity[format.TypeInfo])
+
+	buf := map[string][]format.Report{
+		"lintshell": {
+			{File: "lintshell/test.sh", Line: 1, Type: format.TypeError, Details: "error"},
+			{File: "lintshell/test.sh", Line: 2, Type: format.TypeInfo, Details: "info"},
+		},
+	}
+
+	f.severity(buf, ret)
+	assert.Equal(t, format.TypeWarn, buf["lintshell"][0].Type)
+	assert.Equal(t, format.TypeError, buf["lintshell"][1].Type)
+
+	cfg.Config.Spec.Flow.Override = config.Override{File: ".lintflow.yml"}
+
+	ret = f.override(dir, map[string]interface{}{"config": name})
+	assert.Equal(t, false, ret["lintai"].Disable)
+	assert.Equal(t, 0, len(ret["lintshell"].Filter.Exclude.Paths))
+	assert.Equal(t, 0, len(ret["lintshell"].Severity))
+
+	ret = f.override(dir, map[string]interface{}{})
+	assert.Equal(t, 0, len(ret))
 }
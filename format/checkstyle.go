@@ -0,0 +1,154 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	FormatCheckstyle = "checkstyle"
+)
+
+const (
+	checkstyleVersion = "4.3"
+)
+
+const (
+	checkstyleError   = "error"
+	checkstyleInfo    = "info"
+	checkstyleWarning = "warning"
+)
+
+type checkstyleResult struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string              `xml:"name,attr"`
+	Errors []checkstyleMessage `xml:"error"`
+}
+
+type checkstyleMessage struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// WriteCheckstyle writes the reports of lints as a Checkstyle XML result, one file element per file
+// with the lint name as source.
+func WriteCheckstyle(w io.Writer, data map[string][]Report) error {
+	helper := func(name string) string {
+		switch name {
+		case TypeInfo:
+			return checkstyleInfo
+		case TypeWarn:
+			return checkstyleWarning
+		default:
+			return checkstyleError
+		}
+	}
+
+	files := map[string][]checkstyleMessage{}
+
+	for _, name := range sortedNames(data) {
+		for _, item := range data[name] {
+			files[item.File] = append(files[item.File], checkstyleMessage{
+				Line:     item.Line,
+				Severity: helper(item.Type),
+				Message:  item.Details,
+				Source:   name,
+			})
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for key := range files {
+		names = append(names, key)
+	}
+
+	sort.Strings(names)
+
+	buf := checkstyleResult{
+		Version: checkstyleVersion,
+	}
+
+	for _, name := range names {
+		buf.Files = append(buf.Files, checkstyleFile{Name: name, Errors: files[name]})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(buf); err != nil {
+		return errors.Wrap(err, "failed to encode")
+	}
+
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
+
+// ParseCheckstyle parses a Checkstyle XML result, with the source of each error as rule.
+func ParseCheckstyle(data []byte) ([]Report, error) {
+	helper := func(name string) string {
+		switch name {
+		case checkstyleInfo, "ignore":
+			return TypeInfo
+		case checkstyleWarning:
+			return TypeWarn
+		default:
+			return TypeError
+		}
+	}
+
+	var result checkstyleResult
+
+	if err := xml.Unmarshal(data, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	buf := []Report{}
+
+	for _, file := range result.Files {
+		for _, item := range file.Errors {
+			b := Report{
+				File:    file.Name,
+				Line:    item.Line,
+				Type:    helper(item.Severity),
+				Details: item.Message,
+				Rule:    item.Source,
+			}
+			if item.Line > 0 && item.Column > 0 {
+				b.Range = &Range{StartLine: item.Line, StartColumn: item.Column, EndLine: item.Line, EndColumn: item.Column}
+			}
+			buf = append(buf, b)
+		}
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,77 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCheckstyle(t *testing.T) {
+	data := map[string][]Report{
+		"lintshell": {
+			{File: "lintshell/test.sh", Line: 1, Type: TypeWarn, Details: "warn"},
+		},
+		"lintcommit": {
+			{File: "lintshell/test.sh", Line: 2, Type: TypeInfo, Details: "info"},
+			{File: "COMMIT_MSG", Line: 1, Type: TypeError, Details: "error"},
+		},
+	}
+
+	var b bytes.Buffer
+
+	err := WriteCheckstyle(&b, data)
+	assert.Equal(t, nil, err)
+
+	var buf checkstyleResult
+
+	err = xml.Unmarshal(b.Bytes(), &buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, checkstyleVersion, buf.Version)
+	assert.Equal(t, 2, len(buf.Files))
+	assert.Equal(t, "COMMIT_MSG", buf.Files[0].Name)
+	assert.Equal(t, checkstyleError, buf.Files[0].Errors[0].Severity)
+	assert.Equal(t, "lintshell/test.sh", buf.Files[1].Name)
+	assert.Equal(t, 2, len(buf.Files[1].Errors))
+	assert.Equal(t, "lintcommit", buf.Files[1].Errors[0].Source)
+	assert.Equal(t, checkstyleInfo, buf.Files[1].Errors[0].Severity)
+	assert.Equal(t, "lintshell", buf.Files[1].Errors[1].Source)
+	assert.Equal(t, checkstyleWarning, buf.Files[1].Errors[1].Severity)
+}
+
+func TestParseCheckstyle(t *testing.T) {
+	data := `<?xml version='1.0' encoding='UTF-8'?>
+<checkstyle version='4.5'>
+<file name='lintshell/test.sh' >
+<error line='3' column='6' severity='info' message='Double quote to prevent globbing' source='ShellCheck.SC2086' />
+<error line='1' severity='warning' message='warn' />
+</file>
+</checkstyle>`
+
+	buf, err := ParseCheckstyle([]byte(data))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(buf))
+	assert.Equal(t, "lintshell/test.sh", buf[0].File)
+	assert.Equal(t, 3, buf[0].Line)
+	assert.Equal(t, TypeInfo, buf[0].Type)
+	assert.Equal(t, "ShellCheck.SC2086", buf[0].Rule)
+	assert.Equal(t, 6, buf[0].Range.StartColumn)
+	assert.Equal(t, TypeWarn, buf[1].Type)
+	assert.Equal(t, (*Range)(nil), buf[1].Range)
+
+	_, err = ParseCheckstyle([]byte("invalid"))
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,104 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"crypto/md5" // nolint:gosec
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	codeclimateType = "issue"
+)
+
+const (
+	codeclimateInfo  = "info"
+	codeclimateMajor = "major"
+	codeclimateMinor = "minor"
+)
+
+type codeclimateIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Severity    string              `json:"severity"`
+	Fingerprint string              `json:"fingerprint"`
+	Location    codeclimateLocation `json:"location"`
+}
+
+type codeclimateLocation struct {
+	Path  string           `json:"path"`
+	Lines codeclimateLines `json:"lines"`
+}
+
+type codeclimateLines struct {
+	Begin int `json:"begin"`
+}
+
+// WriteCodeclimate writes the reports of lints as Code Climate issues, as consumed by the
+// GitLab Code Quality report. Fingerprints don't depend on lines to survive code moves.
+func WriteCodeclimate(w io.Writer, data map[string][]Report) error {
+	helper := func(name string) string {
+		switch name {
+		case TypeInfo:
+			return codeclimateInfo
+		case TypeWarn:
+			return codeclimateMinor
+		default:
+			return codeclimateMajor
+		}
+	}
+
+	buf := []codeclimateIssue{}
+	count := map[string]int{}
+
+	for _, name := range sortedNames(data) {
+		for _, item := range data[name] {
+			key := name + "\x00" + item.File + "\x00" + item.Details
+			if n := count[key]; n != 0 {
+				key += "\x00" + strconv.Itoa(n)
+			}
+			count[name+"\x00"+item.File+"\x00"+item.Details]++
+			h := md5.Sum([]byte(key)) // nolint:gosec
+			check := name
+			if item.Rule != "" {
+				check = item.Rule
+			}
+			buf = append(buf, codeclimateIssue{
+				Type:        codeclimateType,
+				CheckName:   check,
+				Description: item.Details,
+				Severity:    helper(item.Type),
+				Fingerprint: hex.EncodeToString(h[:]),
+				Location: codeclimateLocation{
+					Path:  item.File,
+					Lines: codeclimateLines{Begin: max(item.Line, 1)},
+				},
+			})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(buf); err != nil {
+		return errors.Wrap(err, "failed to encode")
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCodeclimate(t *testing.T) {
+	data := map[string][]Report{
+		"lintshell": {
+			{File: "lintshell/test.sh", Line: 1, Type: TypeWarn, Details: "warn"},
+			{File: "lintshell/test.sh", Line: 3, Type: TypeWarn, Details: "warn"},
+			{File: "lintshell/test.sh", Line: 0, Type: TypeError, Details: "error"},
+		},
+	}
+
+	var b bytes.Buffer
+
+	err := WriteCodeclimate(&b, data)
+	assert.Equal(t, nil, err)
+
+	var buf []codeclimateIssue
+
+	err = json.Unmarshal(b.Bytes(), &buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(buf))
+	assert.Equal(t, codeclimateType, buf[0].Type)
+	assert.Equal(t, "lintshell", buf[0].CheckName)
+	assert.Equal(t, codeclimateMinor, buf[0].Severity)
+	assert.NotEqual(t, buf[0].Fingerprint, buf[1].Fingerprint)
+	assert.Equal(t, codeclimateMajor, buf[2].Severity)
+	assert.Equal(t, 1, buf[2].Location.Lines.Begin)
+
+	b.Reset()
+
+	err = WriteCodeclimate(&b, map[string][]Report{"lintshell": data["lintshell"][1:]})
+	assert.Equal(t, nil, err)
+
+	var ret []codeclimateIssue
+
+	err = json.Unmarshal(b.Bytes(), &ret)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, buf[0].Fingerprint, ret[0].Fingerprint)
+}
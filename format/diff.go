@@ -0,0 +1,55 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/reviewdog/reviewdog/diff"
+)
+
+const (
+	diffBin    = "Binary files differ"
+	diffPrefix = "b/"
+	diffSep    = "diff --git"
+)
+
+// ParseDiffs parses the file diffs of a patch such as git format-patch output, binary files are skipped.
+func ParseDiffs(patch []byte) ([]*diff.FileDiff, error) {
+	index := bytes.Index(patch, []byte(diffSep))
+	if index < 0 {
+		return nil, errors.New("invalid patch")
+	}
+
+	var b []byte
+
+	for _, item := range bytes.SplitAfter(patch[index:], []byte(diffSep)) {
+		if !bytes.Contains(item, []byte(diffBin)) {
+			b = bytes.Join([][]byte{b, item}, []byte(""))
+		}
+	}
+
+	diffs, err := diff.ParseMultiFile(bytes.NewReader(b))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse")
+	}
+
+	return diffs, nil
+}
+
+// DiffPath returns the path of a file diff after the change.
+func DiffPath(d *diff.FileDiff) string {
+	return strings.TrimPrefix(d.PathNew, diffPrefix)
+}
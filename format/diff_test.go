@@ -0,0 +1,46 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDiffs(t *testing.T) {
+	_, err := ParseDiffs([]byte("Subject: [PATCH] Empty\n"))
+	assert.NotEqual(t, nil, err)
+
+	patch := "Subject: [PATCH] Add test files\n" +
+		"---\n" +
+		"\n" +
+		"diff --git a/lintshell/test.png b/lintshell/test.png\n" +
+		"new file mode 100644\n" +
+		"index 0000000..bb54fe5\n" +
+		"Binary files differ\n" +
+		"diff --git a/lintshell/test.sh b/lintshell/test.sh\n" +
+		"new file mode 100755\n" +
+		"index 0000000..bb54fe5\n" +
+		"--- /dev/null\n" +
+		"+++ b/lintshell/test.sh\n" +
+		"@@ -0,0 +1,2 @@\n" +
+		"+#!/bin/bash\n" +
+		"+echo\n"
+
+	diffs, err := ParseDiffs([]byte(patch))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(diffs))
+	assert.Equal(t, "lintshell/test.sh", DiffPath(diffs[0]))
+	assert.Equal(t, 1, len(diffs[0].Hunks))
+}
@@ -12,15 +12,131 @@
 
 package format
 
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	FormatJson = "json"
+)
+
 const (
 	TypeError = "Error"
 	TypeInfo  = "Info"
 	TypeWarn  = "Warn"
 )
 
+var (
+	severities = map[string]int{
+		TypeInfo:  1,
+		TypeWarn:  2,
+		TypeError: 3,
+	}
+)
+
 type Report struct {
-	File    string `json:"file"`
-	Line    int    `json:"line"`
-	Type    string `json:"type"`
-	Details string `json:"details"`
+	File        string       `json:"file"`
+	Line        int          `json:"line"`
+	Type        string       `json:"type"`
+	Details     string       `json:"details"`
+	Rule        string       `json:"rule,omitempty"`
+	Range       *Range       `json:"range,omitempty"`
+	Suggestions []Suggestion `json:"suggestions,omitempty"`
+}
+
+type Range struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+type Suggestion struct {
+	Range Range  `json:"range"`
+	Text  string `json:"text"`
+}
+
+// Severity returns the rank of a report type, unknown types rank as errors.
+func Severity(name string) int {
+	if val, ok := severities[name]; ok {
+		return val
+	}
+
+	return severities[TypeError]
+}
+
+// WriteJson writes the reports of lints as {"lint": [{"file", "line", "type", "details"}]}.
+func WriteJson(w io.Writer, data map[string][]Report) error {
+	buf := map[string][]Report{}
+
+	for key, val := range data {
+		if val == nil {
+			val = []Report{}
+		}
+		buf[key] = val
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(buf); err != nil {
+		return errors.Wrap(err, "failed to encode")
+	}
+
+	return nil
+}
+
+// ParseJson parses the reports written by WriteJson, or a plain list of reports.
+func ParseJson(data []byte) ([]Report, error) {
+	var buf []Report
+
+	if err := json.Unmarshal(data, &buf); err == nil {
+		if buf == nil {
+			buf = []Report{}
+		}
+		return buf, nil
+	}
+
+	var lints map[string][]Report
+
+	if err := json.Unmarshal(data, &lints); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	buf = []Report{}
+
+	for _, name := range sortedNames(lints) {
+		buf = append(buf, lints[name]...)
+	}
+
+	return buf, nil
+}
+
+// WriteText writes the reports of lints as {lint}:{file}:{line}:{type}:{details}, one per line.
+func WriteText(w io.Writer, data map[string][]Report) error {
+	for _, name := range sortedNames(data) {
+		for _, item := range data[name] {
+			if _, err := fmt.Fprintf(w, "%s:%s:%d:%s:%s\n", name, item.File, item.Line, item.Type, item.Details); err != nil {
+				return errors.Wrap(err, "failed to write")
+			}
+		}
+	}
+
+	return nil
+}
+
+func sortedNames(data map[string][]Report) []string {
+	names := make([]string, 0, len(data))
+	for key := range data {
+		names = append(names, key)
+	}
+
+	sort.Strings(names)
+
+	return names
 }
@@ -13,6 +13,8 @@
 package format
 
 import (
+	"bytes"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,3 +23,59 @@ import (
 func TestFormat(t *testing.T) {
 	assert.Equal(t, nil, nil)
 }
+
+func TestSeverity(t *testing.T) {
+	assert.Greater(t, Severity(TypeError), Severity(TypeWarn))
+	assert.Greater(t, Severity(TypeWarn), Severity(TypeInfo))
+	assert.Equal(t, Severity(TypeError), Severity("Unknown"))
+}
+
+func TestWriteJson(t *testing.T) {
+	data := map[string][]Report{
+		"lintshell": {
+			{File: "lintshell/test.sh", Line: 1, Type: TypeError, Details: "error"},
+		},
+		"lintcpp": nil,
+	}
+
+	var b bytes.Buffer
+
+	err := WriteJson(&b, data)
+	assert.Equal(t, nil, err)
+
+	buf := map[string][]Report{}
+	err = json.Unmarshal(b.Bytes(), &buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, data["lintshell"], buf["lintshell"])
+	assert.Equal(t, []Report{}, buf["lintcpp"])
+}
+
+func TestWriteText(t *testing.T) {
+	data := map[string][]Report{
+		"lintshell": {
+			{File: "lintshell/test.sh", Line: 1, Type: TypeError, Details: "error"},
+		},
+		"lintcommit": {
+			{File: "COMMIT_MSG", Line: 2, Type: TypeWarn, Details: "warn"},
+		},
+	}
+
+	var b bytes.Buffer
+
+	err := WriteText(&b, data)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "lintcommit:COMMIT_MSG:2:Warn:warn\nlintshell:lintshell/test.sh:1:Error:error\n", b.String())
+}
+
+func TestParseJson(t *testing.T) {
+	buf, err := ParseJson([]byte(`{"lintshell": [{"file": "lintshell/test.sh", "line": 1, "type": "Error", "details": "error"}]}`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []Report{{File: "lintshell/test.sh", Line: 1, Type: TypeError, Details: "error"}}, buf)
+
+	buf, err = ParseJson([]byte(`[{"file": "COMMIT_MSG", "line": 2, "type": "Warn", "details": "warn"}]`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []Report{{File: "COMMIT_MSG", Line: 2, Type: TypeWarn, Details: "warn"}}, buf)
+
+	_, err = ParseJson([]byte("invalid"))
+	assert.NotEqual(t, nil, err)
+}
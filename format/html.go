@@ -0,0 +1,273 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/reviewdog/reviewdog/diff"
+)
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>lintflow {{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #202124; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #dadce0; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f1f3f4; }
+pre { margin: 0; }
+.patch { border: 1px solid #dadce0; font-family: monospace; font-size: 12px; margin-bottom: 1em; }
+.patch div { white-space: pre; }
+.hunk { background: #f1f8ff; color: #5f6368; }
+.add { background: #e6ffed; }
+.del { background: #ffeef0; }
+.finding {
+  background: #fff8c5; border-left: 4px solid #f9ab00; font-family: sans-serif; padding: 2px 8px; white-space: pre-wrap !important;
+}
+.Error { color: #d93025; }
+.Warn { color: #e37400; }
+.Info { color: #1a73e8; }
+</style>
+</head>
+<body>
+<h1>lintflow {{.Title}}</h1>
+<table>
+{{- range .Meta}}
+<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{- end}}
+</table>
+<h2>Lints</h2>
+<table>
+<tr><th>Lint</th><th>Findings</th><th>Time</th></tr>
+{{- range .Lints}}
+<tr><td><a href="#{{.Name}}">{{.Name}}</a></td><td>{{.Count}}</td><td>{{.Time}}</td></tr>
+{{- end}}
+</table>
+{{- range .Lints}}
+{{- $lint := .Name}}
+<h2 id="{{.Name}}">{{.Name}}</h2>
+{{- range .Files}}
+<h3>{{.Name}}</h3>
+<table>
+<tr><th>Line</th><th>Type</th><th>Details</th></tr>
+{{- range .Reports}}
+<tr><td>{{.Line}}</td><td class="{{.Type}}">{{.Type}}</td><td><pre>{{.Details}}</pre></td></tr>
+{{- end}}
+</table>
+{{- if .Hunks}}
+<div class="patch">
+{{- range .Hunks}}
+<div class="hunk">{{.Header}}</div>
+{{- range .Lines}}
+<div class="{{.Class}}">{{.Text}}</div>
+{{- range .Reports}}
+<div class="finding"><span class="{{.Type}}">{{.Type}}</span> {{$lint}}: {{.Details}}</div>
+{{- end}}
+{{- end}}
+{{- end}}
+</div>
+{{- end}}
+{{- end}}
+{{- end}}
+</body>
+</html>
+`
+
+// Html is the data of a flow run rendered by WriteHtml.
+type Html struct {
+	Meta    map[string]interface{}
+	Patch   []byte
+	Reports map[string][]Report
+	Timings map[string]time.Duration
+}
+
+type htmlView struct {
+	Title string
+	Meta  [][2]string
+	Lints []htmlLint
+}
+
+type htmlLint struct {
+	Name  string
+	Count int
+	Time  string
+	Files []htmlFile
+}
+
+type htmlFile struct {
+	Name    string
+	Reports []Report
+	Hunks   []htmlHunk
+}
+
+type htmlHunk struct {
+	Header string
+	Lines  []htmlLine
+}
+
+type htmlLine struct {
+	Class   string
+	Text    string
+	Reports []Report
+}
+
+// WriteHtml writes a self-contained HTML report of a flow run, with the findings of each lint
+// grouped by file and inlined in the patch hunks.
+func WriteHtml(w io.Writer, data *Html) error {
+	tpl, err := template.New("html").Parse(htmlTemplate)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse")
+	}
+
+	if err := tpl.Execute(w, htmlBuild(data)); err != nil {
+		return errors.Wrap(err, "failed to execute")
+	}
+
+	return nil
+}
+
+func htmlBuild(data *Html) *htmlView {
+	view := &htmlView{}
+
+	if project, ok := data.Meta["project"].(string); ok {
+		view.Title = project
+	}
+
+	keys := make([]string, 0, len(data.Meta))
+	for key := range data.Meta {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		view.Meta = append(view.Meta, [2]string{key, htmlValue(data.Meta[key])})
+	}
+
+	diffs := htmlDiffs(data.Patch)
+
+	for _, name := range sortedNames(data.Reports) {
+		l := htmlLint{
+			Name:  name,
+			Count: len(data.Reports[name]),
+			Time:  data.Timings[name].Round(time.Millisecond).String(),
+		}
+		files := map[string][]Report{}
+		for _, item := range data.Reports[name] {
+			files[item.File] = append(files[item.File], item)
+		}
+		names := make([]string, 0, len(files))
+		for key := range files {
+			names = append(names, key)
+		}
+		sort.Strings(names)
+		for _, file := range names {
+			l.Files = append(l.Files, htmlFile{
+				Name:    file,
+				Reports: files[file],
+				Hunks:   htmlHunks(diffs[file], files[file]),
+			})
+		}
+		view.Lints = append(view.Lints, l)
+	}
+
+	return view
+}
+
+func htmlValue(data interface{}) string {
+	switch val := data.(type) {
+	case string:
+		return val
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var buf []string
+		for _, key := range keys {
+			buf = append(buf, key+": "+htmlValue(val[key]))
+		}
+		return strings.Join(buf, ", ")
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func htmlDiffs(patch []byte) map[string]*diff.FileDiff {
+	buf := map[string]*diff.FileDiff{}
+
+	diffs, err := ParseDiffs(patch)
+	if err != nil {
+		return buf
+	}
+
+	for _, item := range diffs {
+		buf[DiffPath(item)] = item
+	}
+
+	return buf
+}
+
+func htmlHunks(data *diff.FileDiff, reports []Report) []htmlHunk {
+	if data == nil {
+		return nil
+	}
+
+	lines := map[int][]Report{}
+	for _, item := range reports {
+		lines[item.Line] = append(lines[item.Line], item)
+	}
+
+	var buf []htmlHunk
+
+	for _, h := range data.Hunks {
+		hunk := htmlHunk{
+			Header: fmt.Sprintf("@@ -%d,%d +%d,%d @@ %s", h.StartLineOld, h.LineLengthOld, h.StartLineNew, h.LineLengthNew, h.Section),
+		}
+		found := false
+		for _, l := range h.Lines {
+			line := htmlLine{}
+			switch l.Type {
+			case diff.LineAdded:
+				line.Class = "add"
+				line.Text = "+" + l.Content
+				line.Reports = lines[l.LnumNew]
+			case diff.LineDeleted:
+				line.Class = "del"
+				line.Text = "-" + l.Content
+			default:
+				line.Text = " " + l.Content
+				line.Reports = lines[l.LnumNew]
+			}
+			if len(line.Reports) != 0 {
+				found = true
+			}
+			hunk.Lines = append(hunk.Lines, line)
+		}
+		if found {
+			buf = append(buf, hunk)
+		}
+	}
+
+	return buf
+}
@@ -0,0 +1,68 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteHtml(t *testing.T) {
+	patch := "From 533cf5cfdfe047d2689e33c5e624325c3d9ffe38 Mon Sep 17 00:00:00 2001\n" +
+		"Subject: [PATCH] Add test files\n" +
+		"---\n" +
+		"\n" +
+		"diff --git a/lintshell/test.sh b/lintshell/test.sh\n" +
+		"new file mode 100755\n" +
+		"index 0000000..bb54fe5\n" +
+		"--- /dev/null\n" +
+		"+++ b/lintshell/test.sh\n" +
+		"@@ -0,0 +1,3 @@\n" +
+		"+#!/bin/bash\n" +
+		"+\n" +
+		"+echo \"Hello <Shell>!\"\n"
+
+	data := &Html{
+		Meta: map[string]interface{}{
+			"branch":  "master",
+			"project": "lintshell",
+		},
+		Patch: []byte(patch),
+		Reports: map[string][]Report{
+			"lintshell": {
+				{File: "lintshell/test.sh", Line: 3, Type: TypeError, Details: "quote <this>"},
+				{File: "COMMIT_MSG", Line: 1, Type: TypeWarn, Details: "subject"},
+			},
+		},
+		Timings: map[string]time.Duration{
+			"lintshell": 1500 * time.Millisecond,
+		},
+	}
+
+	var b bytes.Buffer
+
+	err := WriteHtml(&b, data)
+	assert.Equal(t, nil, err)
+
+	buf := b.String()
+	assert.Contains(t, buf, "<title>lintflow lintshell</title>")
+	assert.Contains(t, buf, "<td>master</td>")
+	assert.Contains(t, buf, "<td>1.5s</td>")
+	assert.Contains(t, buf, "&#43;echo &#34;Hello &lt;Shell&gt;!&#34;")
+	assert.Contains(t, buf, "lintshell: quote &lt;this&gt;")
+	assert.Contains(t, buf, "<h3>COMMIT_MSG</h3>")
+	assert.NotContains(t, buf, "<Shell>")
+}
@@ -0,0 +1,118 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	junitName = "lintflow"
+)
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string       `xml:"name,attr"`
+	ClassName string       `xml:"classname,attr"`
+	Failure   junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJunit writes the reports of lints as a JUnit XML result, one testsuite per lint
+// and one failing testcase per file with findings.
+func WriteJunit(w io.Writer, data map[string][]Report) error {
+	buf := junitTestSuites{
+		Name: junitName,
+	}
+
+	for _, name := range sortedNames(data) {
+		files := map[string][]Report{}
+		for _, item := range data[name] {
+			files[item.File] = append(files[item.File], item)
+		}
+		names := make([]string, 0, len(files))
+		for key := range files {
+			names = append(names, key)
+		}
+		sort.Strings(names)
+		suite := junitTestSuite{
+			Name:     name,
+			Tests:    len(names),
+			Failures: len(names),
+		}
+		for _, file := range names {
+			var text []string
+			severity := TypeInfo
+			for _, item := range files[file] {
+				text = append(text, fmt.Sprintf("%s:%d:%s:%s", item.File, item.Line, item.Type, item.Details))
+				if Severity(item.Type) > Severity(severity) {
+					severity = item.Type
+				}
+			}
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				Name:      file,
+				ClassName: name,
+				Failure: junitFailure{
+					Message: fmt.Sprintf("%d findings", len(files[file])),
+					Type:    severity,
+					Text:    strings.Join(text, "\n"),
+				},
+			})
+		}
+		buf.Tests += suite.Tests
+		buf.Failures += suite.Failures
+		buf.TestSuites = append(buf.TestSuites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(buf); err != nil {
+		return errors.Wrap(err, "failed to encode")
+	}
+
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteJunit(t *testing.T) {
+	data := map[string][]Report{
+		"lintshell": {
+			{File: "lintshell/test.sh", Line: 1, Type: TypeWarn, Details: "warn"},
+			{File: "lintshell/test.sh", Line: 3, Type: TypeError, Details: "error"},
+			{File: "lintshell/build.sh", Line: 1, Type: TypeInfo, Details: "info"},
+		},
+		"lintcpp": {},
+	}
+
+	var b bytes.Buffer
+
+	err := WriteJunit(&b, data)
+	assert.Equal(t, nil, err)
+
+	var buf junitTestSuites
+
+	err = xml.Unmarshal(b.Bytes(), &buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, buf.Tests)
+	assert.Equal(t, 2, buf.Failures)
+	assert.Equal(t, 2, len(buf.TestSuites))
+	assert.Equal(t, "lintcpp", buf.TestSuites[0].Name)
+	assert.Equal(t, 0, len(buf.TestSuites[0].TestCases))
+	assert.Equal(t, "lintshell", buf.TestSuites[1].Name)
+	assert.Equal(t, "lintshell/build.sh", buf.TestSuites[1].TestCases[0].Name)
+	assert.Equal(t, TypeInfo, buf.TestSuites[1].TestCases[0].Failure.Type)
+	assert.Equal(t, "lintshell/test.sh", buf.TestSuites[1].TestCases[1].Name)
+	assert.Equal(t, TypeError, buf.TestSuites[1].TestCases[1].Failure.Type)
+	assert.Equal(t, "2 findings", buf.TestSuites[1].TestCases[1].Failure.Message)
+}
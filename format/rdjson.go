@@ -0,0 +1,121 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"bufio"
+	"bytes"
+
+	"github.com/pkg/errors"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	FormatRdjson  = "rdjson"
+	FormatRdjsonl = "rdjsonl"
+)
+
+// ParseRdjson parses the reviewdog diagnostic format, i.e. a JSON DiagnosticResult.
+func ParseRdjson(data []byte) ([]Report, error) {
+	var result rdf.DiagnosticResult
+
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	buf := []Report{}
+
+	for _, item := range result.GetDiagnostics() {
+		if item.GetSeverity() == rdf.Severity_UNKNOWN_SEVERITY {
+			item.Severity = result.GetSeverity()
+		}
+		buf = append(buf, rdjsonReport(item))
+	}
+
+	return buf, nil
+}
+
+// ParseRdjsonl parses the reviewdog diagnostic format in JSON Lines, i.e. one Diagnostic per line.
+func ParseRdjsonl(data []byte) ([]Report, error) {
+	buf := []Report{}
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(make([]byte, bufio.MaxScanTokenSize), len(data)+1)
+
+	for s.Scan() {
+		if len(bytes.TrimSpace(s.Bytes())) == 0 {
+			continue
+		}
+		var item rdf.Diagnostic
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(s.Bytes(), &item); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal")
+		}
+		buf = append(buf, rdjsonReport(&item))
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+
+	return buf, nil
+}
+
+func rdjsonReport(data *rdf.Diagnostic) Report {
+	helper := func(data *rdf.Range) *Range {
+		if data == nil || data.GetStart() == nil {
+			return nil
+		}
+		buf := &Range{
+			StartLine:   int(data.GetStart().GetLine()),
+			StartColumn: int(data.GetStart().GetColumn()),
+			EndLine:     int(data.GetStart().GetLine()),
+			EndColumn:   int(data.GetStart().GetColumn()),
+		}
+		if data.GetEnd() != nil {
+			buf.EndLine = int(data.GetEnd().GetLine())
+			buf.EndColumn = int(data.GetEnd().GetColumn())
+		}
+		return buf
+	}
+
+	buf := Report{
+		File:    data.GetLocation().GetPath(),
+		Type:    TypeError,
+		Details: data.GetMessage(),
+		Rule:    data.GetCode().GetValue(),
+		Range:   helper(data.GetLocation().GetRange()),
+	}
+
+	switch data.GetSeverity() {
+	case rdf.Severity_INFO:
+		buf.Type = TypeInfo
+	case rdf.Severity_WARNING:
+		buf.Type = TypeWarn
+	default:
+	}
+
+	if buf.Range != nil {
+		buf.Line = buf.Range.StartLine
+	}
+
+	for _, item := range data.GetSuggestions() {
+		s := Suggestion{Text: item.GetText()}
+		if r := helper(item.GetRange()); r != nil {
+			s.Range = *r
+		}
+		buf.Suggestions = append(buf.Suggestions, s)
+	}
+
+	return buf
+}
@@ -0,0 +1,83 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRdjson(t *testing.T) {
+	data := `{
+		"source": {"name": "shellcheck"},
+		"severity": "WARNING",
+		"diagnostics": [
+			{
+				"message": "Double quote to prevent globbing",
+				"location": {
+					"path": "lintshell/test.sh",
+					"range": {"start": {"line": 3, "column": 6}, "end": {"line": 3, "column": 10}}
+				},
+				"code": {"value": "SC2086"},
+				"suggestions": [
+					{"range": {"start": {"line": 3, "column": 6}, "end": {"line": 3, "column": 10}}, "text": "\"$1\""}
+				]
+			},
+			{
+				"message": "Missing shebang",
+				"location": {"path": "lintshell/test.sh", "range": {"start": {"line": 1}}},
+				"severity": "ERROR"
+			}
+		]
+	}`
+
+	buf, err := ParseRdjson([]byte(data))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(buf))
+
+	assert.Equal(t, "lintshell/test.sh", buf[0].File)
+	assert.Equal(t, 3, buf[0].Line)
+	assert.Equal(t, TypeWarn, buf[0].Type)
+	assert.Equal(t, "SC2086", buf[0].Rule)
+	assert.Equal(t, &Range{StartLine: 3, StartColumn: 6, EndLine: 3, EndColumn: 10}, buf[0].Range)
+	assert.Equal(t, 1, len(buf[0].Suggestions))
+	assert.Equal(t, "\"$1\"", buf[0].Suggestions[0].Text)
+	assert.Equal(t, 6, buf[0].Suggestions[0].Range.StartColumn)
+
+	assert.Equal(t, 1, buf[1].Line)
+	assert.Equal(t, TypeError, buf[1].Type)
+	assert.Equal(t, &Range{StartLine: 1, EndLine: 1}, buf[1].Range)
+
+	_, err = ParseRdjson([]byte("invalid"))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestParseRdjsonl(t *testing.T) {
+	data := `{"message": "info", "location": {"path": "a.go", "range": {"start": {"line": 2}}}, "severity": "INFO"}
+
+{"message": "unknown", "location": {"path": "b.go"}}
+`
+
+	buf, err := ParseRdjsonl([]byte(data))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(buf))
+	assert.Equal(t, TypeInfo, buf[0].Type)
+	assert.Equal(t, 2, buf[0].Line)
+	assert.Equal(t, TypeError, buf[1].Type)
+	assert.Equal(t, 0, buf[1].Line)
+	assert.Equal(t, (*Range)(nil), buf[1].Range)
+
+	_, err = ParseRdjsonl([]byte("{\"message\": 1}"))
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,139 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+const (
+	sarifError   = "error"
+	sarifNote    = "note"
+	sarifWarning = "warning"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// WriteSarif writes the reports of lints as a SARIF 2.1.0 log, one run per lint.
+func WriteSarif(w io.Writer, data map[string][]Report) error {
+	helper := func(name string) string {
+		switch name {
+		case TypeInfo:
+			return sarifNote
+		case TypeWarn:
+			return sarifWarning
+		default:
+			return sarifError
+		}
+	}
+
+	buf := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{},
+	}
+
+	for _, name := range sortedNames(data) {
+		run := sarifRun{
+			Tool:    sarifTool{Driver: sarifDriver{Name: name}},
+			Results: []sarifResult{},
+		}
+		for _, item := range data[name] {
+			l := sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{Uri: item.File},
+				},
+			}
+			if item.Line > 0 {
+				l.PhysicalLocation.Region = &sarifRegion{StartLine: item.Line}
+				if r := item.Range; r != nil && r.StartLine == item.Line && r.StartColumn > 0 {
+					l.PhysicalLocation.Region.StartColumn = r.StartColumn
+					l.PhysicalLocation.Region.EndLine = r.EndLine
+					l.PhysicalLocation.Region.EndColumn = r.EndColumn
+				}
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleId:    item.Rule,
+				Level:     helper(item.Type),
+				Message:   sarifMessage{Text: item.Details},
+				Locations: []sarifLocation{l},
+			})
+		}
+		buf.Runs = append(buf.Runs, run)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(buf); err != nil {
+		return errors.Wrap(err, "failed to encode")
+	}
+
+	return nil
+}
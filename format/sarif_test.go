@@ -0,0 +1,53 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSarif(t *testing.T) {
+	data := map[string][]Report{
+		"lintshell": {
+			{File: "lintshell/test.sh", Line: 1, Type: TypeWarn, Details: "warn", Rule: "SC2086",
+				Range: &Range{StartLine: 1, StartColumn: 6, EndLine: 1, EndColumn: 10}},
+			{File: "COMMIT_MSG", Line: 0, Type: TypeError, Details: "error"},
+		},
+		"lintcpp": {},
+	}
+
+	var b bytes.Buffer
+
+	err := WriteSarif(&b, data)
+	assert.Equal(t, nil, err)
+
+	var buf sarifLog
+
+	err = json.Unmarshal(b.Bytes(), &buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, sarifVersion, buf.Version)
+	assert.Equal(t, 2, len(buf.Runs))
+	assert.Equal(t, "lintcpp", buf.Runs[0].Tool.Driver.Name)
+	assert.Equal(t, 0, len(buf.Runs[0].Results))
+	assert.Equal(t, "lintshell", buf.Runs[1].Tool.Driver.Name)
+	assert.Equal(t, sarifWarning, buf.Runs[1].Results[0].Level)
+	assert.Equal(t, "SC2086", buf.Runs[1].Results[0].RuleId)
+	assert.Equal(t, 1, buf.Runs[1].Results[0].Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, 6, buf.Runs[1].Results[0].Locations[0].PhysicalLocation.Region.StartColumn)
+	assert.Equal(t, sarifError, buf.Runs[1].Results[1].Level)
+	assert.Equal(t, (*sarifRegion)(nil), buf.Runs[1].Results[1].Locations[0].PhysicalLocation.Region)
+}
@@ -5,8 +5,10 @@ go 1.23.1
 require (
 	github.com/alecthomas/kingpin/v2 v2.4.0
 	github.com/pkg/errors v0.9.1
+	github.com/reviewdog/errorformat v0.0.0-20240608101709-1d3280ed6bd4
 	github.com/reviewdog/reviewdog v0.20.2
 	github.com/stretchr/testify v1.9.0
+	golang.org/x/time v0.6.0
 	google.golang.org/grpc v1.67.0
 	google.golang.org/protobuf v1.34.2
 	gopkg.in/yaml.v3 v3.0.1
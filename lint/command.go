@@ -0,0 +1,184 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/reviewdog/errorformat"
+	"github.com/reviewdog/errorformat/fmts"
+
+	"github.com/devops-lintflow/lintflow/config"
+	"github.com/devops-lintflow/lintflow/format"
+)
+
+const (
+	commandDir        = ".command"
+	formatErrorformat = "errorformat"
+)
+
+// command runs a local command over the files in root, with the files appended to its arguments.
+// The base64 files are decoded into a scratch tree under root, where the command runs.
+// A non-zero exit status is expected from tools reporting findings, unless nothing is output.
+func (l *lint) command(ctx context.Context, lint config.Lint, root string, files []string) (map[string][]format.Report, error) {
+	if len(lint.Command.Exec) == 0 {
+		return nil, errors.New("invalid exec")
+	}
+
+	dir := filepath.Join(root, commandDir, lint.Name)
+
+	defer func() {
+		_ = os.RemoveAll(dir)
+		_ = os.Remove(filepath.Join(root, commandDir))
+	}()
+
+	if err := l.scratch(root, dir, files); err != nil {
+		return nil, errors.Wrap(err, "failed to scratch")
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	args := append(append([]string{}, lint.Command.Exec[1:]...), files...)
+
+	cmd := exec.CommandContext(ctx, lint.Command.Exec[0], args...) // nolint:gosec
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var e *exec.ExitError
+		if !errors.As(err, &e) || len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, errors.Wrap(err, "failed to run: "+msg)
+			}
+			return nil, errors.Wrap(err, "failed to run")
+		}
+	}
+
+	reports, err := l.parse(lint.Command, stdout.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse")
+	}
+
+	for i := range reports {
+		if filepath.IsAbs(reports[i].File) {
+			if rel, err := filepath.Rel(dir, reports[i].File); err == nil {
+				reports[i].File = rel
+			}
+		}
+		reports[i].File = filepath.ToSlash(reports[i].File)
+	}
+
+	return map[string][]format.Report{lint.Name: reports}, nil
+}
+
+// scratch decodes the base64 files in root into dir, keeping their paths and modes.
+func (l *lint) scratch(root, dir string, files []string) error {
+	for _, item := range files {
+		fi, err := os.Stat(filepath.Join(root, item))
+		if err != nil {
+			return errors.Wrap(err, "failed to stat")
+		}
+		buf, err := os.ReadFile(filepath.Join(root, item))
+		if err != nil {
+			return errors.Wrap(err, "failed to read")
+		}
+		dec := make([]byte, base64.StdEncoding.DecodedLen(len(buf)))
+		n, err := base64.StdEncoding.Decode(dec, bytes.TrimSpace(buf))
+		if err != nil {
+			return errors.Wrap(err, "failed to decode")
+		}
+		path := filepath.Join(dir, item)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return errors.Wrap(err, "failed to make")
+		}
+		if err := os.WriteFile(path, dec[:n], fi.Mode().Perm()); err != nil {
+			return errors.Wrap(err, "failed to write")
+		}
+	}
+
+	return nil
+}
+
+func (l *lint) parse(cmd config.Command, data []byte) ([]format.Report, error) {
+	switch cmd.Format {
+	case format.FormatCheckstyle:
+		return format.ParseCheckstyle(data)
+	case format.FormatJson:
+		return format.ParseJson(data)
+	case format.FormatRdjson:
+		return format.ParseRdjson(data)
+	case format.FormatRdjsonl:
+		return format.ParseRdjsonl(data)
+	case formatErrorformat, "":
+		return l.errorformat(cmd.Errorformat, data)
+	default:
+		if f, ok := fmts.DefinedFmts()[cmd.Format]; ok {
+			return l.errorformat(f.Errorformat, data)
+		}
+		return nil, errors.New("invalid format " + cmd.Format)
+	}
+}
+
+// errorformat parses the output with Vim errorformat patterns, see :help errorformat.
+func (l *lint) errorformat(efms []string, data []byte) ([]format.Report, error) {
+	helper := func(t rune) string {
+		switch t {
+		case 'i', 'I', 'n', 'N':
+			return format.TypeInfo
+		case 'w', 'W':
+			return format.TypeWarn
+		default:
+			return format.TypeError
+		}
+	}
+
+	if len(efms) == 0 {
+		return nil, errors.New("invalid errorformat")
+	}
+
+	efm, err := errorformat.NewErrorformat(efms)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to new errorformat")
+	}
+
+	buf := []format.Report{}
+
+	s := efm.NewScanner(bytes.NewReader(data))
+
+	for s.Scan() {
+		e := s.Entry()
+		if !e.Valid || e.Filename == "" {
+			continue
+		}
+		b := format.Report{
+			File:    e.Filename,
+			Line:    e.Lnum,
+			Type:    helper(e.Type),
+			Details: e.Text,
+		}
+		if e.Lnum > 0 && e.Col > 0 {
+			b.Range = &format.Range{StartLine: e.Lnum, StartColumn: e.Col, EndLine: max(e.EndLnum, e.Lnum), EndColumn: max(e.EndCol, e.Col)}
+		}
+		buf = append(buf, b)
+	}
+
+	return buf, nil
+}
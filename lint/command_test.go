@@ -0,0 +1,105 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-lintflow/lintflow/config"
+	"github.com/devops-lintflow/lintflow/format"
+)
+
+func TestCommand(t *testing.T) {
+	l := lint{}
+
+	root := "../tests/project"
+	files := []string{"lintshell/test.sh"}
+
+	lint := config.Lint{
+		Name: "lintshell",
+		Command: config.Command{
+			Exec:        []string{"sh", "-c", "echo \"$1:3:6: warning: Double quote\"; exit 1", "sh"},
+			Errorformat: []string{"%f:%l:%c: %t%*[^:]: %m"},
+		},
+	}
+
+	buf, err := l.command(context.Background(), lint, root, files)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf[lint.Name]))
+	assert.Equal(t, "lintshell/test.sh", buf[lint.Name][0].File)
+	assert.Equal(t, 3, buf[lint.Name][0].Line)
+	assert.Equal(t, format.TypeWarn, buf[lint.Name][0].Type)
+	assert.Equal(t, "Double quote", buf[lint.Name][0].Details)
+	assert.Equal(t, 6, buf[lint.Name][0].Range.StartColumn)
+
+	lint.Command.Exec = []string{"sh", "-c", "exit 2"}
+
+	_, err = l.command(context.Background(), lint, root, files)
+	assert.NotEqual(t, nil, err)
+
+	lint.Command.Exec = nil
+
+	_, err = l.command(context.Background(), lint, root, files)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestCommandDecode(t *testing.T) {
+	l := lint{}
+
+	root := "../tests/project"
+	files := []string{"lintshell/test.sh"}
+
+	lint := config.Lint{
+		Name: "lintgrep",
+		Command: config.Command{
+			Exec:        []string{"grep", "-Hn", "Hello Shell"},
+			Errorformat: []string{"%f:%l:%m"},
+		},
+	}
+
+	buf, err := l.command(context.Background(), lint, root, files)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf[lint.Name]))
+	assert.Equal(t, "lintshell/test.sh", buf[lint.Name][0].File)
+	assert.Equal(t, 3, buf[lint.Name][0].Line)
+	assert.Contains(t, buf[lint.Name][0].Details, "echo \"Hello Shell!\"")
+
+	_, err = os.Stat(filepath.Join(root, commandDir))
+	assert.Equal(t, true, os.IsNotExist(err))
+}
+
+func TestParse(t *testing.T) {
+	l := lint{}
+
+	buf, err := l.parse(config.Command{Format: "golangci-lint"}, []byte("main.go:1:2: unused (deadcode)\n"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf))
+	assert.Equal(t, "main.go", buf[0].File)
+
+	data := `[{"file": "main.go", "line": 1, "type": "Info", "details": "info"}]`
+
+	buf, err = l.parse(config.Command{Format: format.FormatJson}, []byte(data))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, format.TypeInfo, buf[0].Type)
+
+	_, err = l.parse(config.Command{Format: "invalid"}, nil)
+	assert.NotEqual(t, nil, err)
+
+	_, err = l.parse(config.Command{}, nil)
+	assert.NotEqual(t, nil, err)
+}
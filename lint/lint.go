@@ -14,11 +14,14 @@ package lint
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"io"
 	"math"
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
@@ -28,9 +31,15 @@ import (
 	"github.com/devops-lintflow/lintflow/format"
 )
 
+// metadata is the part of meta used to build requests.
+type metadata struct {
+	Base    string            `json:"base"`
+	Renamed map[string]string `json:"renamed"`
+}
+
 type Lint interface {
 	Run(context.Context, string, string, []string, string, string,
-		func(*config.Filter, string, string) bool) (map[string][]format.Report, error)
+		func(*config.Lint, string, string) bool) (map[string][]format.Report, map[string]time.Duration, error)
 }
 
 type Config struct {
@@ -53,11 +62,11 @@ func DefaultConfig() *Config {
 
 // nolint:gocyclo
 func (l *lint) Run(ctx context.Context, root, repo string, files []string, meta, patch string,
-	match func(*config.Filter, string, string) bool) (map[string][]format.Report, error) {
-	helper := func(filter *config.Filter, files []string) []string {
+	match func(*config.Lint, string, string) bool) (map[string][]format.Report, map[string]time.Duration, error) {
+	helper := func(lint *config.Lint, files []string) []string {
 		var buf []string
 		for _, item := range files {
-			if match(filter, repo, item) {
+			if match(lint, repo, item) {
 				buf = append(buf, item)
 			}
 		}
@@ -65,7 +74,9 @@ func (l *lint) Run(ctx context.Context, root, repo string, files []string, meta,
 	}
 
 	type result struct {
+		name string
 		data map[string][]format.Report
+		time time.Duration
 		err  error
 	}
 
@@ -73,44 +84,57 @@ func (l *lint) Run(ctx context.Context, root, repo string, files []string, meta,
 	ch := make(chan result, len(l.cfg.Lints))
 
 	for i := range l.cfg.Lints {
-		buf := helper(&l.cfg.Lints[i].Filter, files)
+		buf := helper(&l.cfg.Lints[i], files)
 		if len(buf) != 0 {
 			bypass = false
 		}
 		go func(ctx context.Context, lint config.Lint, files []string, meta, patch string) {
-			if len(files) != 0 {
+			if len(files) != 0 && len(lint.Command.Exec) != 0 {
+				t := time.Now()
+				rep, err := l.command(ctx, lint, root, files)
+				if err != nil {
+					ch <- result{lint.Name, nil, 0, errors.Wrap(err, "failed to command")}
+					return
+				}
+				ch <- result{lint.Name, rep, time.Since(t), nil}
+			} else if len(files) != 0 {
+				t := time.Now()
 				req, err := l.encode(lint.Name, root, files, meta, patch)
 				if err != nil {
-					ch <- result{nil, errors.Wrap(err, "failed to encode")}
+					ch <- result{lint.Name, nil, 0, errors.Wrap(err, "failed to encode")}
 					return
 				}
 				ret, err := l.routine(ctx, lint.Host, lint.Port, req)
 				if err != nil {
-					ch <- result{nil, errors.Wrap(err, "failed to routine")}
+					ch <- result{lint.Name, nil, 0, errors.Wrap(err, "failed to routine")}
 					return
 				}
 				rep, err := l.decode(ret)
 				if err != nil {
-					ch <- result{nil, errors.Wrap(err, "failed to decode")}
+					ch <- result{lint.Name, nil, 0, errors.Wrap(err, "failed to decode")}
 					return
 				}
-				ch <- result{rep, nil}
+				ch <- result{lint.Name, rep, time.Since(t), nil}
 			} else {
-				ch <- result{map[string][]format.Report{}, nil}
+				ch <- result{lint.Name, map[string][]format.Report{}, 0, nil}
 			}
 		}(ctx, l.cfg.Lints[i], buf, meta, patch)
 	}
 
 	if bypass {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	ret := map[string][]format.Report{}
+	timings := map[string]time.Duration{}
 
 	for range l.cfg.Lints {
 		rep := <-ch
 		if rep.err != nil {
-			return nil, rep.err
+			return nil, nil, rep.err
+		}
+		if rep.time != 0 {
+			timings[rep.name] += rep.time
 		}
 		if len(rep.data) != 0 {
 			for name, reports := range rep.data {
@@ -123,7 +147,7 @@ func (l *lint) Run(ctx context.Context, root, repo string, files []string, meta,
 		}
 	}
 
-	return ret, nil
+	return ret, timings, nil
 }
 
 func (l *lint) routine(ctx context.Context, host string, port int, request *LintRequest) (*LintReply, error) {
@@ -170,14 +194,27 @@ func (l *lint) encode(lint, root string, files []string, meta, patch string) (*L
 		Name: lint,
 	}
 
+	request.LintMeta = &LintMeta{}
+	request.LintMeta.Path = meta
+
+	request.LintMeta.Content, err = helper(filepath.Join(root, meta))
+	if err != nil {
+		return nil, errors.New("invalid meta")
+	}
+
+	m := l.metadata(request.LintMeta.Content)
+
 	request.LintFiles = []*LintFile{}
 
 	for _, item := range files {
-		buf := LintFile{Path: item}
+		buf := LintFile{Path: item, OldPath: m.Renamed[item]}
 		buf.Content, err = helper(filepath.Join(root, item))
 		if err != nil {
 			break
 		}
+		if m.Base != "" {
+			buf.BaseContent, _ = helper(filepath.Join(root, m.Base, item))
+		}
 		request.LintFiles = append(request.LintFiles, &buf)
 	}
 
@@ -185,14 +222,6 @@ func (l *lint) encode(lint, root string, files []string, meta, patch string) (*L
 		return nil, errors.New("invalid file")
 	}
 
-	request.LintMeta = &LintMeta{}
-	request.LintMeta.Path = meta
-
-	request.LintMeta.Content, err = helper(filepath.Join(root, meta))
-	if err != nil {
-		return nil, errors.New("invalid meta")
-	}
-
 	request.LintPatch = &LintPatch{}
 	request.LintPatch.Path = patch
 
@@ -204,6 +233,22 @@ func (l *lint) encode(lint, root string, files []string, meta, patch string) (*L
 	return request, nil
 }
 
+// metadata returns the base dir and the old paths of renamed files by path, from the base64 meta written by review.
+func (l *lint) metadata(meta []byte) metadata {
+	var buf metadata
+
+	dec := make([]byte, base64.StdEncoding.DecodedLen(len(meta)))
+
+	n, err := base64.StdEncoding.Decode(dec, meta)
+	if err != nil {
+		return buf
+	}
+
+	_ = json.Unmarshal(dec[:n], &buf)
+
+	return buf
+}
+
 func (l *lint) decode(reply *LintReply) (map[string][]format.Report, error) {
 	name := reply.GetName()
 	if name == "" {
@@ -226,5 +271,22 @@ func (l *lint) decode(reply *LintReply) (map[string][]format.Report, error) {
 		buf[name] = append(buf[name], b)
 	}
 
+	if d := reply.GetLintDiagnostic(); d != nil && len(d.GetContent()) != 0 {
+		var b []format.Report
+		var err error
+		switch d.GetFormat() {
+		case format.FormatRdjson:
+			b, err = format.ParseRdjson(d.GetContent())
+		case format.FormatRdjsonl:
+			b, err = format.ParseRdjsonl(d.GetContent())
+		default:
+			return nil, errors.New("invalid diagnostic format " + d.GetFormat())
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse")
+		}
+		buf[name] = append(buf[name], b...)
+	}
+
 	return buf, nil
 }
@@ -96,8 +96,10 @@ type LintFile struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Path    string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Content []byte `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	Path        string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Content     []byte `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	OldPath     string `protobuf:"bytes,3,opt,name=oldPath,proto3" json:"oldPath,omitempty"`
+	BaseContent []byte `protobuf:"bytes,4,opt,name=baseContent,proto3" json:"baseContent,omitempty"`
 }
 
 func (x *LintFile) Reset() {
@@ -146,6 +148,20 @@ func (x *LintFile) GetContent() []byte {
 	return nil
 }
 
+func (x *LintFile) GetOldPath() string {
+	if x != nil {
+		return x.OldPath
+	}
+	return ""
+}
+
+func (x *LintFile) GetBaseContent() []byte {
+	if x != nil {
+		return x.BaseContent
+	}
+	return nil
+}
+
 type LintMeta struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -261,8 +277,9 @@ type LintReply struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name        string        `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	LintReports []*LintReport `protobuf:"bytes,2,rep,name=lintReports,proto3" json:"lintReports,omitempty"`
+	Name           string          `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	LintReports    []*LintReport   `protobuf:"bytes,2,rep,name=lintReports,proto3" json:"lintReports,omitempty"`
+	LintDiagnostic *LintDiagnostic `protobuf:"bytes,3,opt,name=lintDiagnostic,proto3" json:"lintDiagnostic,omitempty"`
 }
 
 func (x *LintReply) Reset() {
@@ -311,6 +328,68 @@ func (x *LintReply) GetLintReports() []*LintReport {
 	return nil
 }
 
+func (x *LintReply) GetLintDiagnostic() *LintDiagnostic {
+	if x != nil {
+		return x.LintDiagnostic
+	}
+	return nil
+}
+
+type LintDiagnostic struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Format  string `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Content []byte `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+}
+
+func (x *LintDiagnostic) Reset() {
+	*x = LintDiagnostic{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lint_lint_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LintDiagnostic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LintDiagnostic) ProtoMessage() {}
+
+func (x *LintDiagnostic) ProtoReflect() protoreflect.Message {
+	mi := &file_lint_lint_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LintDiagnostic.ProtoReflect.Descriptor instead.
+func (*LintDiagnostic) Descriptor() ([]byte, []int) {
+	return file_lint_lint_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *LintDiagnostic) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *LintDiagnostic) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
 type LintReport struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -325,7 +404,7 @@ type LintReport struct {
 func (x *LintReport) Reset() {
 	*x = LintReport{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_lint_lint_proto_msgTypes[5]
+		mi := &file_lint_lint_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -338,7 +417,7 @@ func (x *LintReport) String() string {
 func (*LintReport) ProtoMessage() {}
 
 func (x *LintReport) ProtoReflect() protoreflect.Message {
-	mi := &file_lint_lint_proto_msgTypes[5]
+	mi := &file_lint_lint_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -351,7 +430,7 @@ func (x *LintReport) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LintReport.ProtoReflect.Descriptor instead.
 func (*LintReport) Descriptor() ([]byte, []int) {
-	return file_lint_lint_proto_rawDescGZIP(), []int{5}
+	return file_lint_lint_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *LintReport) GetFile() string {
@@ -397,36 +476,48 @@ var file_lint_lint_proto_rawDesc = []byte{
 	0x74, 0x4d, 0x65, 0x74, 0x61, 0x12, 0x2d, 0x0a, 0x09, 0x6c, 0x69, 0x6e, 0x74, 0x50, 0x61, 0x74,
 	0x63, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x6c, 0x69, 0x6e, 0x74, 0x2e,
 	0x4c, 0x69, 0x6e, 0x74, 0x50, 0x61, 0x74, 0x63, 0x68, 0x52, 0x09, 0x6c, 0x69, 0x6e, 0x74, 0x50,
-	0x61, 0x74, 0x63, 0x68, 0x22, 0x38, 0x0a, 0x08, 0x4c, 0x69, 0x6e, 0x74, 0x46, 0x69, 0x6c, 0x65,
+	0x61, 0x74, 0x63, 0x68, 0x22, 0x74, 0x0a, 0x08, 0x4c, 0x69, 0x6e, 0x74, 0x46, 0x69, 0x6c, 0x65,
 	0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
 	0x70, 0x61, 0x74, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x38,
-	0x0a, 0x08, 0x4c, 0x69, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61,
-	0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x18,
-	0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x39, 0x0a, 0x09, 0x4c, 0x69, 0x6e, 0x74,
-	0x50, 0x61, 0x74, 0x63, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e,
-	0x74, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74,
-	0x65, 0x6e, 0x74, 0x22, 0x53, 0x0a, 0x09, 0x4c, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79,
-	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x32, 0x0a, 0x0b, 0x6c, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6f,
-	0x72, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6c, 0x69, 0x6e, 0x74,
-	0x2e, 0x4c, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x0b, 0x6c, 0x69, 0x6e,
-	0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x22, 0x62, 0x0a, 0x0a, 0x4c, 0x69, 0x6e, 0x74,
-	0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69,
-	0x6e, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x12,
-	0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79,
-	0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x07, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x32, 0x3d, 0x0a, 0x09,
-	0x4c, 0x69, 0x6e, 0x74, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x30, 0x0a, 0x08, 0x53, 0x65, 0x6e,
-	0x64, 0x4c, 0x69, 0x6e, 0x74, 0x12, 0x11, 0x2e, 0x6c, 0x69, 0x6e, 0x74, 0x2e, 0x4c, 0x69, 0x6e,
-	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x6c, 0x69, 0x6e, 0x74, 0x2e,
-	0x4c, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x2a, 0x5a, 0x28, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73,
-	0x2d, 0x6c, 0x69, 0x6e, 0x74, 0x66, 0x6c, 0x6f, 0x77, 0x2f, 0x6c, 0x69, 0x6e, 0x74, 0x66, 0x6c,
-	0x6f, 0x77, 0x2f, 0x6c, 0x69, 0x6e, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x18,
+	0x0a, 0x07, 0x6f, 0x6c, 0x64, 0x50, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6f, 0x6c, 0x64, 0x50, 0x61, 0x74, 0x68, 0x12, 0x20, 0x0a, 0x0b, 0x62, 0x61, 0x73, 0x65,
+	0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x62,
+	0x61, 0x73, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x38, 0x0a, 0x08, 0x4c, 0x69,
+	0x6e, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f,
+	0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e,
+	0x74, 0x65, 0x6e, 0x74, 0x22, 0x39, 0x0a, 0x09, 0x4c, 0x69, 0x6e, 0x74, 0x50, 0x61, 0x74, 0x63,
+	0x68, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22,
+	0x91, 0x01, 0x0a, 0x09, 0x4c, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x32, 0x0a, 0x0b, 0x6c, 0x69, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6c, 0x69, 0x6e, 0x74, 0x2e, 0x4c, 0x69,
+	0x6e, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x0b, 0x6c, 0x69, 0x6e, 0x74, 0x52, 0x65,
+	0x70, 0x6f, 0x72, 0x74, 0x73, 0x12, 0x3c, 0x0a, 0x0e, 0x6c, 0x69, 0x6e, 0x74, 0x44, 0x69, 0x61,
+	0x67, 0x6e, 0x6f, 0x73, 0x74, 0x69, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e,
+	0x6c, 0x69, 0x6e, 0x74, 0x2e, 0x4c, 0x69, 0x6e, 0x74, 0x44, 0x69, 0x61, 0x67, 0x6e, 0x6f, 0x73,
+	0x74, 0x69, 0x63, 0x52, 0x0e, 0x6c, 0x69, 0x6e, 0x74, 0x44, 0x69, 0x61, 0x67, 0x6e, 0x6f, 0x73,
+	0x74, 0x69, 0x63, 0x22, 0x42, 0x0a, 0x0e, 0x4c, 0x69, 0x6e, 0x74, 0x44, 0x69, 0x61, 0x67, 0x6e,
+	0x6f, 0x73, 0x74, 0x69, 0x63, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x18, 0x0a,
+	0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x62, 0x0a, 0x0a, 0x4c, 0x69, 0x6e, 0x74, 0x52,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x32, 0x3d, 0x0a, 0x09, 0x4c,
+	0x69, 0x6e, 0x74, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x30, 0x0a, 0x08, 0x53, 0x65, 0x6e, 0x64,
+	0x4c, 0x69, 0x6e, 0x74, 0x12, 0x11, 0x2e, 0x6c, 0x69, 0x6e, 0x74, 0x2e, 0x4c, 0x69, 0x6e, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x6c, 0x69, 0x6e, 0x74, 0x2e, 0x4c,
+	0x69, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x2a, 0x5a, 0x28, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x2d,
+	0x6c, 0x69, 0x6e, 0x74, 0x66, 0x6c, 0x6f, 0x77, 0x2f, 0x6c, 0x69, 0x6e, 0x74, 0x66, 0x6c, 0x6f,
+	0x77, 0x2f, 0x6c, 0x69, 0x6e, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -441,27 +532,29 @@ func file_lint_lint_proto_rawDescGZIP() []byte {
 	return file_lint_lint_proto_rawDescData
 }
 
-var file_lint_lint_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_lint_lint_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_lint_lint_proto_goTypes = []any{
-	(*LintRequest)(nil), // 0: lint.LintRequest
-	(*LintFile)(nil),    // 1: lint.LintFile
-	(*LintMeta)(nil),    // 2: lint.LintMeta
-	(*LintPatch)(nil),   // 3: lint.LintPatch
-	(*LintReply)(nil),   // 4: lint.LintReply
-	(*LintReport)(nil),  // 5: lint.LintReport
+	(*LintRequest)(nil),    // 0: lint.LintRequest
+	(*LintFile)(nil),       // 1: lint.LintFile
+	(*LintMeta)(nil),       // 2: lint.LintMeta
+	(*LintPatch)(nil),      // 3: lint.LintPatch
+	(*LintReply)(nil),      // 4: lint.LintReply
+	(*LintDiagnostic)(nil), // 5: lint.LintDiagnostic
+	(*LintReport)(nil),     // 6: lint.LintReport
 }
 var file_lint_lint_proto_depIdxs = []int32{
 	1, // 0: lint.LintRequest.lintFiles:type_name -> lint.LintFile
 	2, // 1: lint.LintRequest.lintMeta:type_name -> lint.LintMeta
 	3, // 2: lint.LintRequest.lintPatch:type_name -> lint.LintPatch
-	5, // 3: lint.LintReply.lintReports:type_name -> lint.LintReport
-	0, // 4: lint.LintProto.SendLint:input_type -> lint.LintRequest
-	4, // 5: lint.LintProto.SendLint:output_type -> lint.LintReply
-	5, // [5:6] is the sub-list for method output_type
-	4, // [4:5] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	6, // 3: lint.LintReply.lintReports:type_name -> lint.LintReport
+	5, // 4: lint.LintReply.lintDiagnostic:type_name -> lint.LintDiagnostic
+	0, // 5: lint.LintProto.SendLint:input_type -> lint.LintRequest
+	4, // 6: lint.LintProto.SendLint:output_type -> lint.LintReply
+	6, // [6:7] is the sub-list for method output_type
+	5, // [5:6] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_lint_lint_proto_init() }
@@ -531,6 +624,18 @@ func file_lint_lint_proto_init() {
 			}
 		}
 		file_lint_lint_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*LintDiagnostic); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lint_lint_proto_msgTypes[6].Exporter = func(v any, i int) any {
 			switch v := v.(*LintReport); i {
 			case 0:
 				return &v.state
@@ -549,7 +654,7 @@ func file_lint_lint_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_lint_lint_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
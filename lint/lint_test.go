@@ -13,6 +13,7 @@
 package lint
 
 import (
+	"encoding/base64"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -42,6 +43,19 @@ func TestEncode(t *testing.T) {
 	assert.Equal(t, patch, req.LintPatch.Path)
 }
 
+func TestMetadata(t *testing.T) {
+	l := lint{}
+
+	meta := base64.StdEncoding.EncodeToString([]byte(`{"base":"42-a4bc7bd.base","deleted":["LICENSE"],` +
+		`"renamed":{"lintshell/new.sh":"lintshell/old.sh"}}`))
+
+	m := l.metadata([]byte(meta))
+	assert.Equal(t, "42-a4bc7bd.base", m.Base)
+	assert.Equal(t, map[string]string{"lintshell/new.sh": "lintshell/old.sh"}, m.Renamed)
+
+	assert.Equal(t, metadata{}, l.metadata([]byte("invalid")))
+}
+
 func TestDecode(t *testing.T) {
 	l := lint{}
 
@@ -66,3 +80,28 @@ func TestDecode(t *testing.T) {
 	assert.Equal(t, len(reply.LintReports), len(buf[reply.Name]))
 	assert.Equal(t, reply.LintReports[0].File, buf[reply.Name][0].File)
 }
+
+func TestDecodeDiagnostic(t *testing.T) {
+	l := lint{}
+
+	reply := &LintReply{
+		Name: "lintshell",
+		LintDiagnostic: &LintDiagnostic{
+			Format: format.FormatRdjsonl,
+			Content: []byte(`{"message": "Double quote", ` +
+				`"location": {"path": "lintshell/test.sh", "range": {"start": {"line": 3}}}, "severity": "WARNING"}`),
+		},
+	}
+
+	buf, err := l.decode(reply)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(buf[reply.Name]))
+	assert.Equal(t, "lintshell/test.sh", buf[reply.Name][0].File)
+	assert.Equal(t, 3, buf[reply.Name][0].Line)
+	assert.Equal(t, format.TypeWarn, buf[reply.Name][0].Type)
+
+	reply.LintDiagnostic.Format = "invalid"
+
+	_, err = l.decode(reply)
+	assert.NotEqual(t, nil, err)
+}
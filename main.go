@@ -17,15 +17,26 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/pkg/errors"
+
 	"github.com/devops-lintflow/lintflow/cmd"
+	"github.com/devops-lintflow/lintflow/flow"
+)
+
+const (
+	exitFailure  = 1
+	exitFindings = 2
 )
 
 func main() {
 	ctx := context.Background()
 
 	if err := cmd.Run(ctx); err != nil {
+		if errors.Is(err, flow.ErrFindings) {
+			os.Exit(exitFindings)
+		}
 		fmt.Println(err.Error())
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 
 	os.Exit(0)
@@ -13,22 +13,34 @@
 package review
 
 import (
+	"archive/tar"
 	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha1" // nolint:gosec
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/reviewdog/reviewdog/diff"
+	"golang.org/x/time/rate"
 
 	"github.com/devops-lintflow/lintflow/config"
 	"github.com/devops-lintflow/lintflow/format"
@@ -40,45 +52,107 @@ const (
 )
 
 const (
-	diffBin    = "Binary files differ"
-	diffSep    = "diff --git"
-	diffPrefix = "b/"
-)
-
-const (
+	metaBase      = "base"
 	metaBranch    = "branch"
+	metaConfig    = "config"
+	metaDeleted   = "deleted"
 	metaName      = "name"
 	metaNumber    = "_number"
 	metaOwner     = "owner"
 	metaProject   = "project"
+	metaRenamed   = "renamed"
 	metaRevisions = "revisions"
 	metaUpdated   = "updated"
 	metaUrl       = "url"
 )
 
 const (
-	suffixMeta  = "meta"
-	suffixPatch = "patch"
+	statusAdded   = "A"
+	statusDeleted = "D"
+	statusRenamed = "R"
+)
+
+const (
+	suffixBase   = "base"
+	suffixConfig = "yml"
+	suffixMeta   = "meta"
+	suffixPatch  = "patch"
 )
 
 const (
 	queryLimit   = 1000
+	urlAccount   = "/accounts/self"
+	urlArchive   = "/archive?format=tgz"
+	urlBranches  = "/branches/"
 	urlChanges   = "/changes/"
+	urlComments  = "/comments"
 	urlContent   = "/content"
 	urlDetail    = "/detail"
 	urlFiles     = "/files/"
 	urlNumber    = "&n="
 	urlOption    = "&o="
+	urlParent    = "?parent=1"
 	urlPatch     = "/patch"
 	urlPrefix    = "/a"
+	urlProjects  = "/projects/"
 	urlQuery     = "?q="
 	urlReview    = "/review"
 	urlRevisions = "/revisions/"
 	urlStart     = "&start="
 )
 
+const (
+	fetchWorkers = 8
+)
+
+const (
+	httpTimeout = 60 * time.Second
+	httpAgent   = "lintflow/"
+	httpRetries = 3
+	httpBody    = 512
+)
+
+const (
+	fallbackDiscard = "discard"
+	fallbackFile    = "file"
+	fallbackMessage = "message"
+)
+
+const (
+	commentFixed   = "Fixed in patchset %d"
+	commentLimit   = 5000
+	commentTag     = "lintflow:"
+	policySeverity = format.TypeWarn
+)
+
+var (
+	errNotFound = errors.New("not found")
+)
+
+var (
+	httpBackoff    = time.Second
+	httpBackoffMax = 30 * time.Second
+	limiters       sync.Map
+)
+
 type gerrit struct {
-	r config.Review
+	r        config.Review
+	override string
+	posted   int
+	once     sync.Once
+	hc       *http.Client
+	hcErr    error
+}
+
+// thread is a comment thread started by lintflow on an earlier patchset.
+type thread struct {
+	file        string
+	line        int
+	patchSet    int
+	id          string
+	unresolved  bool
+	fixed       bool
+	fingerprint string
 }
 
 func (g *gerrit) Clean(name string) error {
@@ -89,23 +163,36 @@ func (g *gerrit) Clean(name string) error {
 	return nil
 }
 
+// Fetch writes the files of a change matched by match with project, branch and file, or all files if match is nil.
 // nolint:funlen,gocritic,gocyclo
-func (g *gerrit) Fetch(root, commit string) (dname, rname string, flist []string, mname, pname string, emsg error) {
-	filterFiles := func(data map[string]interface{}) map[string]interface{} {
+func (g *gerrit) Fetch(ctx context.Context, root, commit string, match func(string, string, string) bool) (dname, rname string,
+	flist []string, mname, pname string, emsg error) {
+	deleted := []string{}
+	renamed := map[string]string{}
+
+	filterFiles := func(data map[string]interface{}, project, branch string) map[string]interface{} {
 		buf := make(map[string]interface{})
 		for key, val := range data {
-			if v, ok := val.(map[string]interface{})["status"]; ok {
-				if v.(string) == "D" || v.(string) == "R" {
-					continue
+			info := val.(map[string]interface{})
+			switch v, _ := info["status"].(string); v {
+			case statusDeleted:
+				deleted = append(deleted, key)
+				continue
+			case statusRenamed:
+				if old, ok := info["old_path"].(string); ok {
+					renamed[key] = old
 				}
 			}
+			if match != nil && !match(project, branch, strings.TrimPrefix(key, "/")) {
+				continue
+			}
 			buf[key] = val
 		}
 		return buf
 	}
 
 	// Query commit
-	buf, err := g.get(g.urlQuery(commitQuery+":"+commit, []string{"ALL_COMMITS", "ALL_REVISIONS", "DETAILED_ACCOUNTS"}, 0))
+	buf, err := g.get(ctx, g.urlQuery(commitQuery+":"+commit, []string{"ALL_COMMITS", "ALL_REVISIONS", "DETAILED_ACCOUNTS"}, 0))
 	if err != nil {
 		return "", "", nil, "", "", errors.Wrap(err, "failed to query")
 	}
@@ -116,6 +203,8 @@ func (g *gerrit) Fetch(root, commit string) (dname, rname string, flist []string
 	}
 
 	changeNum := int(queryRet[0].(map[string]interface{})["_number"].(float64))
+	project := queryRet[0].(map[string]interface{})["project"].(string)
+	branch := queryRet[0].(map[string]interface{})["branch"].(string)
 
 	revisions := queryRet[0].(map[string]interface{})["revisions"].(map[string]interface{})
 	current := revisions[commit].(map[string]interface{})
@@ -124,7 +213,7 @@ func (g *gerrit) Fetch(root, commit string) (dname, rname string, flist []string
 	path := filepath.Join(root, strconv.Itoa(changeNum), commit)
 
 	// Get files
-	buf, err = g.get(g.urlFiles(changeNum, revisionNum))
+	buf, err = g.get(ctx, g.urlFiles(changeNum, revisionNum))
 	if err != nil {
 		return "", "", nil, "", "", errors.Wrap(err, "failed to get files")
 	}
@@ -135,23 +224,20 @@ func (g *gerrit) Fetch(root, commit string) (dname, rname string, flist []string
 	}
 
 	// Match files
-	fs = filterFiles(fs)
+	fs = filterFiles(fs, project, branch)
 
 	// Get content
-	for key := range fs {
-		buf, err = g.get(g.urlContent(changeNum, revisionNum, key))
-		if err != nil {
-			return "", "", nil, "", "", errors.Wrap(err, "failed to get content")
-		}
+	if err := g.contents(ctx, path, changeNum, revisionNum, fs); err != nil {
+		return "", "", nil, "", "", errors.Wrap(err, "failed to get content")
+	}
 
-		file := filepath.Base(key)
-		if key == commitMsg {
-			file = strings.TrimPrefix(commitMsg, "/")
-		}
+	// Get base
+	base := ""
 
-		err = g.write(filepath.Join(path, filepath.Dir(key)), file, string(buf))
-		if err != nil {
-			return "", "", nil, "", "", errors.Wrap(err, "failed to write content")
+	if g.r.Fetch.Base {
+		base = fmt.Sprintf("%d-%s.%s", changeNum, commit[:7], suffixBase)
+		if err := g.bases(ctx, path, base, project, g.parent(current), changeNum, revisionNum, fs); err != nil {
+			return "", "", nil, "", "", errors.Wrap(err, "failed to get base")
 		}
 	}
 
@@ -165,8 +251,34 @@ func (g *gerrit) Fetch(root, commit string) (dname, rname string, flist []string
 		}
 	}
 
+	sort.Strings(deleted)
+
+	// Get config
+	extra := map[string]interface{}{
+		metaDeleted: deleted,
+		metaRenamed: renamed,
+	}
+
+	if base != "" {
+		extra[metaBase] = base
+	}
+
+	if g.override != "" {
+		buf, err = g.get(ctx, g.urlBranchContent(project, branch, g.override))
+		if err != nil && !errors.Is(err, errNotFound) {
+			return "", "", nil, "", "", errors.Wrap(err, "failed to get config")
+		}
+		if err == nil {
+			name := fmt.Sprintf("%d-%s.%s", changeNum, commit[:7], suffixConfig)
+			if err := g.write(path, name, string(buf)); err != nil {
+				return "", "", nil, "", "", errors.Wrap(err, "failed to write config")
+			}
+			extra[metaConfig] = name
+		}
+	}
+
 	// Get meta
-	buf, err = g.meta(commit, queryRet[0])
+	buf, err = g.meta(commit, queryRet[0], extra)
 	if err != nil {
 		return "", "", nil, "", "", errors.Wrap(err, "failed to get meta")
 	}
@@ -179,7 +291,7 @@ func (g *gerrit) Fetch(root, commit string) (dname, rname string, flist []string
 	}
 
 	// Get patch
-	buf, err = g.get(g.urlPatch(changeNum, revisionNum))
+	buf, err = g.get(ctx, g.urlPatch(changeNum, revisionNum))
 	if err != nil {
 		return "", "", nil, "", "", errors.Wrap(err, "failed to get patch")
 	}
@@ -191,12 +303,253 @@ func (g *gerrit) Fetch(root, commit string) (dname, rname string, flist []string
 		return "", "", nil, "", "", errors.Wrap(err, "failed to write patch")
 	}
 
-	return path, queryRet[0].(map[string]interface{})["project"].(string), files, meta, patch, nil
+	return path, project, files, meta, patch, nil
+}
+
+// contents writes the content of files to path, from an archive of the revision if enabled and
+// with a pool of workers, errors of all files are returned.
+func (g *gerrit) contents(ctx context.Context, path string, change, revision int, files map[string]interface{}) error {
+	keys := make([]string, 0, len(files))
+
+	for key := range files {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	if g.r.Fetch.Archive {
+		rest, err := g.archive(ctx, path, change, revision, keys)
+		if err != nil {
+			log.Printf("failed to get archive, getting files: %v\n", err)
+		} else {
+			keys = rest
+		}
+	}
+
+	helper := func(key string) error {
+		buf, err := g.get(ctx, g.urlContent(change, revision, key))
+		if err != nil {
+			return errors.Wrap(err, "failed to get")
+		}
+		return g.content(path, key, buf)
+	}
+
+	return g.pool(keys, helper)
+}
+
+// bases writes the content of files before the change to the base dir of path, from the mirror of project
+// at parent if set. Added files and files not found are skipped.
+func (g *gerrit) bases(ctx context.Context, path, base, project, parent string, change, revision int, files map[string]interface{}) error {
+	keys := make([]string, 0, len(files))
+
+	for key, val := range files {
+		if status, _ := val.(map[string]interface{})["status"].(string); key != commitMsg && status != statusAdded {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	helper := func(key string) error {
+		name := key
+		if old, ok := files[key].(map[string]interface{})["old_path"].(string); ok {
+			name = old
+		}
+		var buf []byte
+		var err error
+		if g.r.Fetch.Mirror != "" {
+			buf, err = g.mirror(project, parent, name)
+		} else {
+			buf, err = g.get(ctx, g.urlBaseContent(change, revision, name))
+		}
+		if errors.Is(err, errNotFound) {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to get")
+		}
+		return g.content(filepath.Join(path, base), key, buf)
+	}
+
+	return g.pool(keys, helper)
+}
+
+// mirror returns the base64 content of a file at commit in the bare git mirror of project.
+func (g *gerrit) mirror(project, commit, name string) ([]byte, error) {
+	if commit == "" {
+		return nil, errNotFound
+	}
+
+	dir := filepath.Join(g.r.Fetch.Mirror, project+".git")
+	if _, err := os.Stat(dir); err != nil {
+		dir = filepath.Join(g.r.Fetch.Mirror, project)
+	}
+
+	// nolint:gosec
+	cmd := exec.Command("git", "--git-dir", dir, "cat-file", "blob", commit+":"+name)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(stderr.String(), "does not exist") {
+			return nil, errNotFound
+		}
+		return nil, errors.Wrap(err, strings.TrimSpace(stderr.String()))
+	}
+
+	dst := make([]byte, base64.StdEncoding.EncodedLen(len(out)))
+	base64.StdEncoding.Encode(dst, out)
+
+	return dst, nil
+}
+
+// pool runs helper for keys with a bounded number of workers, and returns the errors of all keys.
+func (g *gerrit) pool(keys []string, helper func(string) error) error {
+	workers := g.r.Fetch.Workers
+	if workers <= 0 {
+		workers = fetchWorkers
+	}
+
+	ch := make(chan string)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []string
+	)
+
+	for i := 0; i < workers && i < len(keys); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range ch {
+				if err := helper(key); err != nil {
+					mu.Lock()
+					errs = append(errs, fmt.Sprintf("%s: %v", key, err))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, key := range keys {
+		ch <- key
+	}
+
+	close(ch)
+	wg.Wait()
+
+	if len(errs) != 0 {
+		sort.Strings(errs)
+		return errors.Errorf("%d of %d files failed:\n%s", len(errs), len(keys), strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+// archive writes the files found in a tgz archive of the revision to path, and returns the others.
+// The archive is extracted as it is downloaded.
+func (g *gerrit) archive(ctx context.Context, path string, change, revision int, keys []string) ([]string, error) {
+	var rest []string
+
+	err := g.stream(ctx, http.MethodGet, g.urlArchive(change, revision), nil, func(r io.Reader) error {
+		var err error
+		rest, err = g.extract(path, r, keys)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get")
+	}
+
+	return rest, nil
+}
+
+// extract writes the files of keys found in a tgz stream to path, and returns the others.
+func (g *gerrit) extract(path string, r io.Reader, keys []string) ([]string, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decompress")
+	}
+
+	defer func() {
+		_ = zr.Close()
+	}()
+
+	wanted := map[string]bool{}
+
+	for _, key := range keys {
+		wanted[key] = true
+	}
+
+	tr := tar.NewReader(zr)
+
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read")
+		}
+		if h.Typeflag != tar.TypeReg || !wanted[h.Name] {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read")
+		}
+		dst := make([]byte, base64.StdEncoding.EncodedLen(len(data)))
+		base64.StdEncoding.Encode(dst, data)
+		if err := g.content(path, h.Name, dst); err != nil {
+			return nil, errors.Wrap(err, "failed to write")
+		}
+		delete(wanted, h.Name)
+	}
+
+	var rest []string
+
+	for _, key := range keys {
+		if wanted[key] {
+			rest = append(rest, key)
+		}
+	}
+
+	return rest, nil
+}
+
+// content writes the base64 content of a file to path.
+func (g *gerrit) content(path, key string, data []byte) error {
+	file := filepath.Base(key)
+	if key == commitMsg {
+		file = strings.TrimPrefix(commitMsg, "/")
+	}
+
+	if err := g.write(filepath.Join(path, filepath.Dir(key)), file, string(data)); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
+
+// parent returns the first parent commit of a revision.
+func (g *gerrit) parent(revision map[string]interface{}) string {
+	commit, _ := revision["commit"].(map[string]interface{})
+	parents, _ := commit["parents"].([]interface{})
+
+	if len(parents) == 0 {
+		return ""
+	}
+
+	buf, _ := parents[0].(map[string]interface{})["commit"].(string)
+
+	return buf
 }
 
-func (g *gerrit) Query(search string, start int) ([]interface{}, error) {
+func (g *gerrit) Query(ctx context.Context, search string, start int) ([]interface{}, error) {
 	helper := func(search string, start int) []interface{} {
-		buf, err := g.get(g.urlQuery(search, []string{"ALL_REVISIONS", "DETAILED_ACCOUNTS"}, start))
+		buf, err := g.get(ctx, g.urlQuery(search, []string{"ALL_REVISIONS", "DETAILED_ACCOUNTS"}, start))
 		if err != nil {
 			return nil
 		}
@@ -221,7 +574,7 @@ func (g *gerrit) Query(search string, start int) ([]interface{}, error) {
 		return buf, nil
 	}
 
-	if b, err := g.Query(search, start+len(buf)); err == nil {
+	if b, err := g.Query(ctx, search, start+len(buf)); err == nil {
 		buf = append(buf, b...)
 	}
 
@@ -229,55 +582,9 @@ func (g *gerrit) Query(search string, start int) ([]interface{}, error) {
 }
 
 // nolint:funlen,gocyclo
-func (g *gerrit) Vote(commit string, data []format.Report, vote config.Vote) error {
-	match := func(data format.Report, diffs []*diff.FileDiff) bool {
-		for _, d := range diffs {
-			if strings.Replace(d.PathNew, diffPrefix, "", 1) != data.File {
-				continue
-			}
-			if data.Line <= 0 {
-				return true
-			}
-			for _, h := range d.Hunks {
-				for _, l := range h.Lines {
-					if l.Type == diff.LineAdded && l.LnumNew == data.Line {
-						return true
-					}
-				}
-			}
-		}
-		return false
-	}
-
-	build := func(data []format.Report, diffs []*diff.FileDiff) (map[string]interface{}, map[string]interface{}, string) {
-		if len(data) == 0 {
-			return nil, map[string]interface{}{vote.Label: vote.Approval}, vote.Message
-		}
-		c := map[string]interface{}{}
-		for _, item := range data {
-			if item.Details == "" || (item.File != commitMsg && !match(item, diffs)) {
-				continue
-			}
-			l := item.Line
-			if l <= 0 {
-				l = 1
-			}
-			b := map[string]interface{}{"line": l, "message": item.Details, "unresolved": false}
-			if _, ok := c[item.File]; !ok {
-				c[item.File] = []map[string]interface{}{b}
-			} else {
-				c[item.File] = append(c[item.File].([]map[string]interface{}), b)
-			}
-		}
-		if len(c) == 0 {
-			return nil, map[string]interface{}{vote.Label: vote.Approval}, vote.Message
-		} else {
-			return c, map[string]interface{}{vote.Label: vote.Disapproval}, vote.Message
-		}
-	}
-
+func (g *gerrit) Vote(ctx context.Context, commit string, data []format.Report, vote config.Vote) error {
 	// Query commit
-	ret, err := g.get(g.urlQuery(commitQuery+":"+commit, []string{"ALL_REVISIONS", "DETAILED_ACCOUNTS"}, 0))
+	ret, err := g.get(ctx, g.urlQuery(commitQuery+":"+commit, []string{"ALL_REVISIONS", "DETAILED_ACCOUNTS"}, 0))
 	if err != nil {
 		return errors.Wrap(err, "failed to query")
 	}
@@ -287,12 +594,20 @@ func (g *gerrit) Vote(commit string, data []format.Report, vote config.Vote) err
 		return errors.Wrap(err, "failed to unmarshalList")
 	}
 
+	changeNum := int(c[0].(map[string]interface{})["_number"].(float64))
+
 	revisions := c[0].(map[string]interface{})["revisions"].(map[string]interface{})
 	current := revisions[commit].(map[string]interface{})
 	revisionNum := int(current["_number"].(float64))
 
+	// Get limit
+	limit, err := g.limit(ctx, changeNum)
+	if err != nil {
+		return errors.Wrap(err, "failed to limit")
+	}
+
 	// Get patch
-	ret, err = g.get(g.urlPatch(int(c[0].(map[string]interface{})["_number"].(float64)), revisionNum))
+	ret, err = g.get(ctx, g.urlPatch(changeNum, revisionNum))
 	if err != nil {
 		return errors.Wrap(err, "failed to patch")
 	}
@@ -303,36 +618,537 @@ func (g *gerrit) Vote(commit string, data []format.Report, vote config.Vote) err
 		return errors.Wrap(err, "failed to decode")
 	}
 
-	index := bytes.Index(dec, []byte(diffSep))
-	if index < 0 {
-		return errors.New("failed to index")
+	diffs, err := format.ParseDiffs(dec)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse")
 	}
 
-	var b []byte
+	// Get files
+	var files map[string]interface{}
 
-	for _, item := range bytes.SplitAfter(dec[index:], []byte(diffSep)) {
-		if !bytes.Contains(item, []byte(diffBin)) {
-			b = bytes.Join([][]byte{b, item}, []byte(""))
+	if g.r.Comment.Fallback == fallbackFile {
+		ret, err = g.get(ctx, g.urlFiles(changeNum, revisionNum))
+		if err != nil {
+			return errors.Wrap(err, "failed to get files")
+		}
+		files, err = g.unmarshal(ret)
+		if err != nil {
+			return errors.Wrap(err, "failed to unmarshal")
 		}
 	}
 
-	diffs, err := diff.ParseMultiFile(bytes.NewReader(b))
+	// Split reports, outside reports are matched with threads as posted
+	reports, outside, messages := g.split(data, diffs, files)
+
+	// Match threads
+	lines := g.lines(ctx, changeNum)
+
+	threads, err := g.threads(ctx, changeNum, commentTag+vote.Label, lines)
 	if err != nil {
-		return errors.Wrap(err, "failed to parse")
+		return errors.Wrap(err, "failed to get threads")
+	}
+
+	found := map[string]bool{}
+	posted := map[string]bool{}
+
+	for _, item := range threads {
+		if !item.fixed {
+			posted[item.fingerprint] = true
+		}
+	}
+
+	if len(threads) != 0 {
+		for _, item := range append(append([]format.Report{}, data...), outside...) {
+			text, err := g.text(lines, revisionNum, item.File, item.Line)
+			if err != nil {
+				return errors.Wrap(err, "failed to get text")
+			}
+			found[g.fingerprint(item.File, text, g.message(item))] = true
+		}
+	}
+
+	exist := func(item format.Report) bool {
+		if len(threads) == 0 {
+			return false
+		}
+		text, _ := g.text(lines, revisionNum, item.File, item.Line)
+		return posted[g.fingerprint(item.File, text, g.message(item))]
+	}
+
+	// Resolve threads
+	replies := map[int]map[string]interface{}{}
+
+	for _, item := range threads {
+		if !item.unresolved || found[item.fingerprint] {
+			continue
+		}
+		if limit == 0 {
+			break
+		}
+		b := map[string]interface{}{
+			"in_reply_to": item.id,
+			"line":        item.line,
+			"message":     fmt.Sprintf(commentFixed, revisionNum),
+			"unresolved":  false,
+		}
+		if _, ok := replies[item.patchSet]; !ok {
+			replies[item.patchSet] = map[string]interface{}{}
+		}
+		if _, ok := replies[item.patchSet][item.file]; !ok {
+			replies[item.patchSet][item.file] = []map[string]interface{}{b}
+		} else {
+			replies[item.patchSet][item.file] = append(replies[item.patchSet][item.file].([]map[string]interface{}), b)
+		}
+		if limit > 0 {
+			limit--
+		}
+	}
+
+	for patchSet, val := range replies {
+		buf := map[string]interface{}{"comments": val, "tag": commentTag + vote.Label}
+		if err := g.post(ctx, g.urlReview(changeNum, patchSet), buf); err != nil {
+			return errors.Wrap(err, "failed to reply")
+		}
 	}
 
 	// Review commit
-	comments, labels, message := build(data, diffs)
-	fmt.Printf("  labels: %v\n", labels)
-	fmt.Printf(" message: %s\n", message)
-	buf := map[string]interface{}{"comments": comments, "labels": labels, "message": message}
-	if err := g.post(g.urlReview(int(c[0].(map[string]interface{})["_number"].(float64)), revisionNum), buf); err != nil {
+	comments, labels, message := g.build(reports, outside, messages, vote, limit, exist)
+	log.Printf("labels: %v\n", labels)
+	log.Printf("message: %s\n", message)
+	buf := map[string]interface{}{"comments": comments, "labels": labels, "message": message, "tag": commentTag + vote.Label}
+	if err := g.post(ctx, g.urlReview(changeNum, revisionNum), buf); err != nil {
 		return errors.Wrap(err, "failed to review")
 	}
 
+	g.posted += g.count(comments)
+
 	return nil
 }
 
+func (g *gerrit) match(data format.Report, diffs []*diff.FileDiff) bool {
+	for _, d := range diffs {
+		if format.DiffPath(d) != data.File {
+			continue
+		}
+		if data.Line <= 0 {
+			return true
+		}
+		for _, h := range d.Hunks {
+			for _, l := range h.Lines {
+				if l.Type == diff.LineAdded && l.LnumNew == data.Line {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// split splits reports into reports in the diff, reports outside the diff posted as file comments
+// with their line in details, and lines of the message, other reports are discarded.
+func (g *gerrit) split(data []format.Report, diffs []*diff.FileDiff,
+	files map[string]interface{}) (reports, outside []format.Report, lines []string) {
+	discard := 0
+
+	for _, item := range data {
+		if item.Details == "" {
+			continue
+		}
+		if item.File == commitMsg || g.match(item, diffs) {
+			reports = append(reports, item)
+			continue
+		}
+		switch g.r.Comment.Fallback {
+		case fallbackFile, fallbackMessage:
+			if _, ok := files[item.File]; ok && g.r.Comment.Fallback == fallbackFile {
+				if item.Line > 0 {
+					item.Details = fmt.Sprintf("Line %d: %s", item.Line, item.Details)
+				}
+				item.Line = 0
+				outside = append(outside, item)
+			} else {
+				lines = append(lines, fmt.Sprintf("%s:%d:%s:%s", item.File, item.Line, item.Type, item.Details))
+			}
+		default:
+			discard++
+		}
+	}
+
+	if discard != 0 {
+		log.Printf("discard: %d\n", discard)
+	}
+
+	return reports, outside, lines
+}
+
+// build builds the comments, labels and message of a review from the reports split by split, posting at most
+// limit comments ordered by severity and summarizing the rest in the message, a negative limit means no limit.
+// Reports already posted are voted but not commented again, and reports outside the diff are not voted.
+// nolint:funlen,gocyclo
+func (g *gerrit) build(reports, outside []format.Report, lines []string,
+	vote config.Vote, limit int, exist func(format.Report) bool) (comments, labels map[string]interface{}, message string) {
+	helper := func(data []format.Report) {
+		sort.SliceStable(data, func(i, j int) bool {
+			return format.Severity(data[i].Type) > format.Severity(data[j].Type)
+		})
+	}
+
+	var rest []format.Report
+
+	helper(reports)
+	helper(outside)
+
+	c := map[string]interface{}{}
+
+	add := func(item format.Report, line int) bool {
+		if exist != nil && exist(item) {
+			return true
+		}
+		if limit >= 0 && g.count(c) >= limit {
+			return false
+		}
+		b := map[string]interface{}{"message": g.message(item), "unresolved": g.unresolved(item)}
+		if line > 0 {
+			b["line"] = line
+			if r := g.span(item, line); r != nil {
+				b["range"] = r
+			}
+		}
+		if _, ok := c[item.File]; !ok {
+			c[item.File] = []map[string]interface{}{b}
+		} else {
+			c[item.File] = append(c[item.File].([]map[string]interface{}), b)
+		}
+		return true
+	}
+
+	for _, item := range reports {
+		if !add(item, max(item.Line, 1)) {
+			rest = append(rest, item)
+		}
+	}
+
+	for _, item := range outside {
+		if !add(item, 0) {
+			rest = append(rest, item)
+		}
+	}
+
+	if len(c) == 0 {
+		c = nil
+	}
+
+	message = vote.Message
+	if len(lines) != 0 {
+		message += "\n\n" + fmt.Sprintf("%d findings outside the diff:\n", len(lines)) + strings.Join(lines, "\n")
+	}
+	if len(rest) != 0 {
+		message += "\n\n" + g.summary(rest)
+	}
+
+	return c, map[string]interface{}{vote.Label: g.score(reports, vote)}, message
+}
+
+func (g *gerrit) count(comments map[string]interface{}) int {
+	var n int
+
+	for _, val := range comments {
+		n += len(val.([]map[string]interface{}))
+	}
+
+	return n
+}
+
+// message appends the suggested replacements of the report to its details.
+func (g *gerrit) message(data format.Report) string {
+	buf := data.Details
+
+	for _, item := range data.Suggestions {
+		buf += fmt.Sprintf("\n\nSuggestion (line %d):\n", item.Range.StartLine)
+		for _, line := range strings.Split(item.Text, "\n") {
+			buf += "    " + line + "\n"
+		}
+	}
+
+	return strings.TrimSuffix(buf, "\n")
+}
+
+// span converts the 1-based columns of the report range into a Gerrit comment range.
+func (g *gerrit) span(data format.Report, line int) map[string]interface{} {
+	r := data.Range
+	if r == nil || r.StartLine != line || r.StartColumn <= 0 || r.EndColumn <= 0 || r.EndLine < r.StartLine {
+		return nil
+	}
+
+	if r.EndLine == r.StartLine && r.EndColumn <= r.StartColumn {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"start_line":      r.StartLine,
+		"start_character": r.StartColumn - 1,
+		"end_line":        r.EndLine,
+		"end_character":   r.EndColumn - 1,
+	}
+}
+
+// summary collapses the reports not posted as comments into counts per file and type, or rule if any.
+func (g *gerrit) summary(data []format.Report) string {
+	count := map[string]int{}
+
+	for _, item := range data {
+		key := g.severity(item.Type)
+		if item.Rule != "" {
+			key = item.Rule
+		}
+		count[item.File+" ("+key+")"]++
+	}
+
+	keys := make([]string, 0, len(count))
+	for key := range count {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	buf := []string{fmt.Sprintf("%d findings not posted due to comment limit:", len(data))}
+	for _, key := range keys {
+		buf = append(buf, fmt.Sprintf("%s: %d", key, count[key]))
+	}
+
+	return strings.Join(buf, "\n")
+}
+
+// limit returns the number of comments allowed for the change, with respect to the
+// comments posted in this run and the comments already existing on the change.
+func (g *gerrit) limit(ctx context.Context, change int) (int, error) {
+	limit := -1
+
+	if g.r.Comment.MaxPerRun > 0 {
+		limit = max(g.r.Comment.MaxPerRun-g.posted, 0)
+	}
+
+	maxPerChange := g.r.Comment.MaxPerChange
+	if maxPerChange <= 0 {
+		maxPerChange = commentLimit
+	}
+
+	buf, err := g.get(ctx, g.urlDetail(change))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get detail")
+	}
+
+	detail, err := g.unmarshal(buf)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	count, _ := detail["total_comment_count"].(float64)
+
+	if n := max(maxPerChange-int(count), 0); limit < 0 || n < limit {
+		limit = n
+	}
+
+	return limit, nil
+}
+
+// score returns the label value for the commented reports: the score of the highest
+// severity reaching its threshold, or the approval if no severity at or above the
+// policy severity does.
+func (g *gerrit) score(data []format.Report, vote config.Vote) string {
+	policy := vote.Policy
+
+	severity := policy.Severity
+	if severity == "" {
+		severity = policySeverity
+	}
+
+	count := map[string]int{}
+
+	for _, item := range data {
+		count[g.severity(item.Type)]++
+	}
+
+	for _, item := range []string{format.TypeError, format.TypeWarn, format.TypeInfo} {
+		if format.Severity(item) < format.Severity(severity) {
+			break
+		}
+		threshold := policy.Threshold[item]
+		if threshold <= 0 {
+			threshold = 1
+		}
+		if count[item] < threshold {
+			continue
+		}
+		if s := policy.Score[item]; s != "" {
+			return s
+		}
+		return vote.Disapproval
+	}
+
+	return vote.Approval
+}
+
+// unresolved reports if a new comment starts unresolved, i.e. its severity is at least
+// the unresolved severity of the config, so that submit requirements can gate on it.
+func (g *gerrit) unresolved(data format.Report) bool {
+	if g.r.Comment.Unresolved == "" {
+		return false
+	}
+
+	return format.Severity(data.Type) >= format.Severity(g.r.Comment.Unresolved)
+}
+
+// severity normalizes a report type, unknown types are treated as errors.
+func (g *gerrit) severity(name string) string {
+	switch name {
+	case format.TypeInfo, format.TypeWarn:
+		return name
+	default:
+		return format.TypeError
+	}
+}
+
+// threads returns the threads tagged with tag and started by the account of lintflow.
+// nolint:gocyclo
+func (g *gerrit) threads(ctx context.Context, change int, tag string, lines func(int, string) ([]string, error)) ([]thread, error) {
+	if !g.auth() {
+		return nil, nil
+	}
+
+	buf, err := g.get(ctx, g.urlAccount())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account")
+	}
+
+	account, err := g.unmarshal(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	buf, err = g.get(ctx, g.urlComments(change))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get comments")
+	}
+
+	comments, err := g.unmarshal(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	var ret []thread
+
+	for file, val := range comments {
+		items := map[string]map[string]interface{}{}
+		for _, item := range val.([]interface{}) {
+			b := item.(map[string]interface{})
+			items[b["id"].(string)] = b
+		}
+		root := func(item map[string]interface{}) map[string]interface{} {
+			for {
+				id, ok := item["in_reply_to"].(string)
+				if !ok {
+					return item
+				}
+				if _, ok := items[id]; !ok {
+					return item
+				}
+				item = items[id]
+			}
+		}
+		threads := map[string]*thread{}
+		updated := map[string]string{}
+		for _, item := range items {
+			r := root(item)
+			author, _ := r["author"].(map[string]interface{})
+			if author["_account_id"] != account["_account_id"] || r["tag"] != tag {
+				continue
+			}
+			id := r["id"].(string)
+			if _, ok := threads[id]; !ok {
+				line, _ := r["line"].(float64)
+				patchSet, _ := r["patch_set"].(float64)
+				message, _ := r["message"].(string)
+				text, err := g.text(lines, int(patchSet), file, int(line))
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to get text")
+				}
+				threads[id] = &thread{
+					file:        file,
+					line:        int(line),
+					patchSet:    int(patchSet),
+					fingerprint: g.fingerprint(file, text, message),
+				}
+			}
+			if u, _ := item["updated"].(string); u >= updated[id] {
+				updated[id] = u
+				threads[id].id = item["id"].(string)
+				threads[id].unresolved, _ = item["unresolved"].(bool)
+				a, _ := item["author"].(map[string]interface{})
+				m, _ := item["message"].(string)
+				threads[id].fixed = !threads[id].unresolved && a["_account_id"] == account["_account_id"] &&
+					strings.HasPrefix(m, strings.TrimSuffix(commentFixed, "%d"))
+			}
+		}
+		for _, item := range threads {
+			ret = append(ret, *item)
+		}
+	}
+
+	return ret, nil
+}
+
+// lines returns a cached getter of the file lines in a revision of the change.
+func (g *gerrit) lines(ctx context.Context, change int) func(int, string) ([]string, error) {
+	cache := map[string][]string{}
+
+	return func(revision int, file string) ([]string, error) {
+		key := strconv.Itoa(revision) + ":" + file
+		if buf, ok := cache[key]; ok {
+			return buf, nil
+		}
+		buf, err := g.get(ctx, g.urlContent(change, revision, file))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get content")
+		}
+		dec := make([]byte, base64.StdEncoding.DecodedLen(len(buf)))
+		n, err := base64.StdEncoding.Decode(dec, buf)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode")
+		}
+		cache[key] = strings.Split(string(dec[:n]), "\n")
+		return cache[key], nil
+	}
+}
+
+// text returns the trimmed text of the line commented, lines out of range and files not found have no text.
+func (g *gerrit) text(lines func(int, string) ([]string, error), revision int, file string, line int) (string, error) {
+	if line <= 0 {
+		line = 1
+	}
+
+	buf, err := lines(revision, file)
+	if errors.Is(err, errNotFound) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if line > len(buf) {
+		return "", nil
+	}
+
+	return strings.TrimSpace(buf[line-1]), nil
+}
+
+func (g *gerrit) fingerprint(file, text, message string) string {
+	h := sha1.Sum([]byte(file + "\x00" + text + "\x00" + strings.TrimSpace(message)))
+	return hex.EncodeToString(h[:])
+}
+
 func (g *gerrit) write(dir, file, data string) error {
 	_ = os.MkdirAll(dir, os.ModePerm)
 
@@ -375,11 +1191,49 @@ func (g *gerrit) unmarshalList(data []byte) ([]interface{}, error) {
 	return buf, nil
 }
 
+func (g *gerrit) urlAccount() string {
+	return g.r.Url + urlPrefix + urlAccount
+}
+
+func (g *gerrit) urlComments(change int) string {
+	buf := g.r.Url + urlChanges + strconv.Itoa(change) + urlComments
+
+	if g.auth() {
+		buf = g.r.Url + urlPrefix + urlChanges + strconv.Itoa(change) + urlComments
+	}
+
+	return buf
+}
+
+func (g *gerrit) urlArchive(change, revision int) string {
+	buf := g.r.Url + urlChanges + strconv.Itoa(change) +
+		urlRevisions + strconv.Itoa(revision) + urlArchive
+
+	if g.auth() {
+		buf = g.r.Url + urlPrefix + urlChanges + strconv.Itoa(change) +
+			urlRevisions + strconv.Itoa(revision) + urlArchive
+	}
+
+	return buf
+}
+
+func (g *gerrit) urlBranchContent(project, branch, name string) string {
+	buf := g.r.Url + urlProjects + url.QueryEscape(project) +
+		urlBranches + url.QueryEscape(branch) + urlFiles + url.QueryEscape(name) + urlContent
+
+	if g.auth() {
+		buf = g.r.Url + urlPrefix + urlProjects + url.QueryEscape(project) +
+			urlBranches + url.QueryEscape(branch) + urlFiles + url.QueryEscape(name) + urlContent
+	}
+
+	return buf
+}
+
 func (g *gerrit) urlContent(change, revision int, name string) string {
 	buf := g.r.Url + urlChanges + strconv.Itoa(change) +
 		urlRevisions + strconv.Itoa(revision) + urlFiles + url.QueryEscape(name) + urlContent
 
-	if g.r.User != "" && g.r.Pass != "" {
+	if g.auth() {
 		buf = g.r.Url + urlPrefix + urlChanges + strconv.Itoa(change) +
 			urlRevisions + strconv.Itoa(revision) + urlFiles + url.QueryEscape(name) + urlContent
 	}
@@ -387,10 +1241,14 @@ func (g *gerrit) urlContent(change, revision int, name string) string {
 	return buf
 }
 
+func (g *gerrit) urlBaseContent(change, revision int, name string) string {
+	return g.urlContent(change, revision, name) + urlParent
+}
+
 func (g *gerrit) urlDetail(change int) string {
 	buf := g.r.Url + urlChanges + strconv.Itoa(change) + urlDetail
 
-	if g.r.User != "" && g.r.Pass != "" {
+	if g.auth() {
 		buf = g.r.Url + urlPrefix + urlChanges + strconv.Itoa(change) + urlDetail
 	}
 
@@ -401,7 +1259,7 @@ func (g *gerrit) urlFiles(change, revision int) string {
 	buf := g.r.Url + urlChanges + strconv.Itoa(change) +
 		urlRevisions + strconv.Itoa(revision) + urlFiles
 
-	if g.r.User != "" && g.r.Pass != "" {
+	if g.auth() {
 		buf = g.r.Url + urlPrefix + urlChanges + strconv.Itoa(change) +
 			urlRevisions + strconv.Itoa(revision) + urlFiles
 	}
@@ -413,7 +1271,7 @@ func (g *gerrit) urlPatch(change, revision int) string {
 	buf := g.r.Url + urlChanges + strconv.Itoa(change) +
 		urlRevisions + strconv.Itoa(revision) + urlPatch
 
-	if g.r.User != "" && g.r.Pass != "" {
+	if g.auth() {
 		buf = g.r.Url + urlPrefix + urlChanges + strconv.Itoa(change) +
 			urlRevisions + strconv.Itoa(revision) + urlPatch
 	}
@@ -428,7 +1286,7 @@ func (g *gerrit) urlQuery(search string, option []string, start int) string {
 		urlNumber + strconv.Itoa(queryLimit)
 
 	buf := g.r.Url + urlChanges + query
-	if g.r.User != "" && g.r.Pass != "" {
+	if g.auth() {
 		buf = g.r.Url + urlPrefix + urlChanges + query
 	}
 
@@ -439,7 +1297,7 @@ func (g *gerrit) urlReview(change, revision int) string {
 	buf := g.r.Url + urlChanges + strconv.Itoa(change) +
 		urlRevisions + strconv.Itoa(revision) + urlReview
 
-	if g.r.User != "" && g.r.Pass != "" {
+	if g.auth() {
 		buf = g.r.Url + urlPrefix + urlChanges + strconv.Itoa(change) +
 			urlRevisions + strconv.Itoa(revision) + urlReview
 	}
@@ -447,7 +1305,7 @@ func (g *gerrit) urlReview(change, revision int) string {
 	return buf
 }
 
-func (g *gerrit) meta(rev string, _query interface{}) ([]byte, error) {
+func (g *gerrit) meta(rev string, _query interface{}, extra map[string]interface{}) ([]byte, error) {
 	helper := func(offset int) string {
 		sign := "+"
 		if offset < 0 {
@@ -488,6 +1346,10 @@ func (g *gerrit) meta(rev string, _query interface{}) ([]byte, error) {
 		metaUrl:     g.r.Url,
 	}
 
+	for key, val := range extra {
+		buf[key] = val
+	}
+
 	ret, err := json.Marshal(buf)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to marshal")
@@ -499,70 +1361,233 @@ func (g *gerrit) meta(rev string, _query interface{}) ([]byte, error) {
 	return dst, nil
 }
 
-func (g *gerrit) get(_url string) ([]byte, error) {
-	req, err := http.NewRequest(http.MethodGet, _url, http.NoBody)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to request")
-	}
+// auth reports whether requests are authenticated, with a token, a cookie or with user and password.
+func (g *gerrit) auth() bool {
+	return g.r.Token != "" || g.r.Cookie != "" || (g.r.User != "" && g.r.Pass != "")
+}
 
-	if g.r.User != "" && g.r.Pass != "" {
+func (g *gerrit) authorize(req *http.Request) {
+	if g.r.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.r.Token)
+	} else if g.r.Cookie != "" {
+		req.Header.Set("Cookie", g.r.Cookie)
+	} else if g.r.User != "" && g.r.Pass != "" {
 		req.SetBasicAuth(g.r.User, g.r.Pass)
 	}
+}
 
-	rsp, err := http.DefaultClient.Do(req)
+// client returns the http client of review, which is created on first use.
+func (g *gerrit) client() (*http.Client, error) {
+	helper := func() (*http.Client, error) {
+		timeout := httpTimeout
+		if g.r.Http.Timeout != "" {
+			t, err := time.ParseDuration(g.r.Http.Timeout)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse timeout")
+			}
+			timeout = t
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if g.r.Http.Proxy != "" {
+			u, err := url.Parse(g.r.Http.Proxy)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse proxy")
+			}
+			transport.Proxy = http.ProxyURL(u)
+		}
+		transport.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: g.r.Http.Insecure, // nolint:gosec
+			MinVersion:         tls.VersionTLS12,
+		}
+		if g.r.Http.CaFile != "" {
+			buf, err := os.ReadFile(g.r.Http.CaFile)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to read caFile")
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(buf) {
+				return nil, errors.New("invalid caFile")
+			}
+			transport.TLSClientConfig.RootCAs = pool
+		}
+		return &http.Client{Transport: transport, Timeout: timeout}, nil
+	}
+
+	g.once.Do(func() {
+		g.hc, g.hcErr = helper()
+	})
+
+	return g.hc, g.hcErr
+}
+
+// do sends a request with authentication and User-Agent set.
+func (g *gerrit) do(req *http.Request) (*http.Response, error) {
+	c, err := g.client()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to do")
+		return nil, errors.Wrap(err, "failed to create client")
 	}
 
-	defer func() {
-		_ = rsp.Body.Close()
-	}()
+	agent := g.r.Http.UserAgent
+	if agent == "" {
+		agent = httpAgent + config.Version
+	}
+
+	req.Header.Set("User-Agent", agent)
+
+	g.authorize(req)
 
-	if rsp.StatusCode != http.StatusOK {
-		return nil, errors.New("invalid status")
+	if l := g.limiter(); l != nil {
+		if err := l.Wait(req.Context()); err != nil {
+			return nil, errors.Wrap(err, "failed to wait")
+		}
 	}
 
-	data, err := io.ReadAll(rsp.Body)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to read")
+	return c.Do(req)
+}
+
+// limiter returns the rate limiter of review, which is shared by the flows of the same url.
+func (g *gerrit) limiter() *rate.Limiter {
+	if g.r.Http.Rate <= 0 {
+		return nil
+	}
+
+	burst := int(g.r.Http.Rate)
+	if burst < 1 {
+		burst = 1
 	}
 
-	return data, nil
+	l, _ := limiters.LoadOrStore(fmt.Sprintf("%s %g", g.r.Url, g.r.Http.Rate), rate.NewLimiter(rate.Limit(g.r.Http.Rate), burst))
+
+	return l.(*rate.Limiter)
 }
 
-func (g *gerrit) post(_url string, data map[string]interface{}) error {
-	buf, err := json.Marshal(data)
-	if err != nil {
-		return errors.Wrap(err, "failed to marshal")
+// request sends a request and returns the response body, see stream.
+func (g *gerrit) request(ctx context.Context, method, _url string, data []byte) ([]byte, error) {
+	var buf []byte
+
+	err := g.stream(ctx, method, _url, data, func(r io.Reader) error {
+		var err error
+		buf, err = io.ReadAll(r)
+		return err
+	})
+
+	return buf, err
+}
+
+// stream sends a request and passes the response body to read, and retries it with exponential backoff
+// or after Retry-After on 429, 5xx and network errors. Posts are retried only if not processed, on 429 and 503.
+func (g *gerrit) stream(ctx context.Context, method, _url string, data []byte, read func(io.Reader) error) error {
+	helper := func() (bool, time.Duration, error) {
+		req, err := http.NewRequestWithContext(ctx, method, _url, bytes.NewReader(data))
+		if err != nil {
+			return false, 0, errors.Wrap(err, "failed to request")
+		}
+		if data != nil {
+			req.Header.Set("Content-Type", "application/json;charset=utf-8")
+		}
+		rsp, err := g.do(req)
+		if err != nil {
+			var e *tls.CertificateVerificationError
+			return method == http.MethodGet && !errors.As(err, &e), 0, errors.Wrap(err, "failed to do")
+		}
+		defer func() {
+			_ = rsp.Body.Close()
+		}()
+		if rsp.StatusCode == http.StatusOK {
+			if err := read(rsp.Body); err != nil {
+				return method == http.MethodGet, 0, errors.Wrap(err, "failed to read")
+			}
+			return false, 0, nil
+		}
+		buf, _ := io.ReadAll(rsp.Body)
+		switch code := rsp.StatusCode; {
+		case code == http.StatusNotFound:
+			return false, 0, errNotFound
+		case code == http.StatusTooManyRequests, code == http.StatusServiceUnavailable:
+			return true, g.retryAfter(rsp.Header.Get("Retry-After")), g.status(code, buf)
+		case code >= http.StatusInternalServerError:
+			return method == http.MethodGet, 0, g.status(code, buf)
+		default:
+			return false, 0, g.status(code, buf)
+		}
 	}
 
-	req, err := http.NewRequest(http.MethodPost, _url, bytes.NewBuffer(buf))
-	if err != nil {
-		return errors.Wrap(err, "failed to request")
+	if _, err := g.client(); err != nil {
+		return errors.Wrap(err, "failed to create client")
+	}
+
+	retries := g.r.Http.Retries
+	if retries == 0 {
+		retries = httpRetries
 	}
 
-	req.Header.Set("Content-Type", "application/json;charset=utf-8")
+	backoff := httpBackoff
 
-	if g.r.User != "" && g.r.Pass != "" {
-		req.SetBasicAuth(g.r.User, g.r.Pass)
+	for i := 0; ; i++ {
+		retry, wait, err := helper()
+		if err == nil || !retry || i >= retries {
+			return err
+		}
+		if wait <= 0 {
+			wait = backoff
+			backoff *= 2
+		}
+		if wait > httpBackoffMax {
+			wait = httpBackoffMax
+		}
+		log.Printf("retrying %s %s in %s: %v\n", method, _url, wait, err)
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return errors.Wrap(ctx.Err(), "failed to retry")
+		case <-t.C:
+		}
 	}
+}
 
-	rsp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return errors.Wrap(err, "failed to do")
+// retryAfter returns the delay of a Retry-After header in seconds or as a date, or 0 if invalid.
+func (g *gerrit) retryAfter(data string) time.Duration {
+	if data == "" {
+		return 0
 	}
 
-	defer func() {
-		_ = rsp.Body.Close()
-	}()
+	if n, err := strconv.Atoi(data); err == nil {
+		return time.Duration(n) * time.Second
+	}
 
-	if rsp.StatusCode != http.StatusOK {
-		return errors.New("invalid status")
+	if t, err := http.ParseTime(data); err == nil {
+		return time.Until(t)
 	}
 
-	_, err = io.ReadAll(rsp.Body)
+	return 0
+}
+
+// status returns an error with the status code and the beginning of body.
+func (g *gerrit) status(code int, body []byte) error {
+	buf := strings.TrimSpace(string(body))
+	if len(buf) > httpBody {
+		buf = buf[:httpBody] + "..."
+	}
+
+	return errors.Errorf("invalid status %d: %s", code, buf)
+}
+
+func (g *gerrit) get(ctx context.Context, _url string) ([]byte, error) {
+	return g.request(ctx, http.MethodGet, _url, nil)
+}
+
+func (g *gerrit) post(ctx context.Context, _url string, data map[string]interface{}) error {
+	buf, err := json.Marshal(data)
 	if err != nil {
-		return errors.Wrap(err, "failed to read")
+		return errors.Wrap(err, "failed to marshal")
+	}
+
+	if _, err := g.request(ctx, http.MethodPost, _url, buf); err != nil {
+		return err
 	}
 
 	return nil
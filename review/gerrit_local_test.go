@@ -0,0 +1,632 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package review
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/reviewdog/reviewdog/diff"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-lintflow/lintflow/config"
+	"github.com/devops-lintflow/lintflow/format"
+)
+
+const (
+	commitLocal = "aed052e8b66810795d3a894a9095db41e5854b70"
+)
+
+func TestClient(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("User-Agent") + " " + r.Header.Get("Cookie")))
+	}))
+	defer s.Close()
+
+	h := gerrit{r: config.Review{Cookie: "o=cookie"}}
+	_, err := h.get(context.Background(), s.URL)
+	assert.NotEqual(t, nil, err)
+
+	h = gerrit{r: config.Review{Cookie: "o=cookie", Http: config.Http{Insecure: true, UserAgent: "agent"}}}
+	buf, err := h.get(context.Background(), s.URL)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "agent o=cookie", string(buf))
+
+	name := filepath.Join(t.TempDir(), "ca.pem")
+	err = os.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw}), 0600)
+	assert.Equal(t, nil, err)
+
+	h = gerrit{r: config.Review{Http: config.Http{CaFile: name, Timeout: "5s"}}}
+	buf, err = h.get(context.Background(), s.URL)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, strings.HasPrefix(string(buf), httpAgent))
+
+	h = gerrit{r: config.Review{Http: config.Http{CaFile: filepath.Join(t.TempDir(), "invalid.pem")}}}
+	_, err = h.get(context.Background(), s.URL)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestRequest(t *testing.T) {
+	backoff := httpBackoff
+	httpBackoff = time.Millisecond
+
+	t.Cleanup(func() {
+		httpBackoff = backoff
+	})
+
+	count := 0
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		switch r.URL.Path {
+		case "/busy":
+			if count < 3 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/slow":
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		case "/invalid":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("invalid label"))
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer s.Close()
+
+	h := gerrit{r: config.Review{Url: s.URL, Http: config.Http{Rate: 100}}}
+
+	buf, err := h.get(context.Background(), s.URL+"/busy")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "ok", string(buf))
+	assert.Equal(t, 3, count)
+
+	count = 0
+	_, err = h.get(context.Background(), s.URL+"/error")
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, httpRetries+1, count)
+
+	count = 0
+	err = h.post(context.Background(), s.URL+"/error", map[string]interface{}{})
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, 1, count)
+
+	count = 0
+	_, err = h.get(context.Background(), s.URL+"/invalid")
+	assert.Equal(t, "invalid status 400: invalid label", err.Error())
+	assert.Equal(t, 1, count)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = h.get(ctx, s.URL+"/slow")
+	assert.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), 10*time.Second)
+
+	assert.Equal(t, 5*time.Second, h.retryAfter("5"))
+	assert.Equal(t, time.Duration(0), h.retryAfter("invalid"))
+}
+
+func TestExtract(t *testing.T) {
+	h := gerrit{}
+
+	r, w := io.Pipe()
+
+	go func() {
+		zw := gzip.NewWriter(w)
+		tw := tar.NewWriter(zw)
+		_ = tw.WriteHeader(&tar.Header{Name: "lintshell/a.sh", Mode: 0600, Size: 7, Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte("archive"))
+		_ = tw.Close()
+		_ = zw.Close()
+		_ = w.Close()
+	}()
+
+	dir := t.TempDir()
+
+	rest, err := h.extract(dir, r, []string{"lintshell/a.sh", "lintshell/b.sh"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"lintshell/b.sh"}, rest)
+
+	buf, err := os.ReadFile(filepath.Join(dir, "lintshell", "a.sh"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("archive")), string(buf))
+
+	_, err = h.extract(dir, strings.NewReader("invalid"), nil)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestContents(t *testing.T) {
+	archive := func() []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(zw)
+		_ = tw.WriteHeader(&tar.Header{Name: "lintshell/a.sh", Mode: 0600, Size: 7, Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte("archive"))
+		_ = tw.Close()
+		_ = zw.Close()
+		return buf.Bytes()
+	}
+
+	var mu sync.Mutex
+
+	count := 0
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/archive") {
+			_, _ = w.Write(archive())
+			return
+		}
+		if strings.Contains(r.URL.Path, "fail") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString([]byte("content"))))
+	}))
+	defer s.Close()
+
+	files := map[string]interface{}{
+		"/COMMIT_MSG":    map[string]interface{}{},
+		"lintshell/a.sh": map[string]interface{}{},
+		"lintshell/b.sh": map[string]interface{}{},
+	}
+
+	dir := t.TempDir()
+	h := gerrit{r: config.Review{Url: s.URL, Fetch: config.Fetch{Workers: 2}}}
+
+	err := h.contents(context.Background(), dir, 1, 1, files)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, count)
+
+	buf, err := os.ReadFile(filepath.Join(dir, "lintshell", "a.sh"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("content")), string(buf))
+
+	count = 0
+	h = gerrit{r: config.Review{Url: s.URL, Fetch: config.Fetch{Archive: true}}}
+
+	err = h.contents(context.Background(), dir, 1, 1, files)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, count)
+
+	buf, err = os.ReadFile(filepath.Join(dir, "lintshell", "a.sh"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("archive")), string(buf))
+
+	_, err = os.Stat(filepath.Join(dir, "COMMIT_MSG"))
+	assert.Equal(t, nil, err)
+
+	files["fail/a.sh"] = map[string]interface{}{}
+	files["fail/b.sh"] = map[string]interface{}{}
+
+	err = h.contents(context.Background(), dir, 1, 1, files)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, true, strings.HasPrefix(err.Error(), "2 of 4 files failed:\nfail/a.sh: "))
+}
+
+func TestBases(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("parent") != "1" || strings.Contains(r.URL.Path, "missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString([]byte(r.URL.Path))))
+	}))
+	defer s.Close()
+
+	files := map[string]interface{}{
+		"/COMMIT_MSG":       map[string]interface{}{},
+		"lintshell/a.sh":    map[string]interface{}{"status": "A"},
+		"lintshell/b.sh":    map[string]interface{}{},
+		"lintshell/c.sh":    map[string]interface{}{"status": "R", "old_path": "lintshell/old.sh"},
+		"lintshell/missing": map[string]interface{}{},
+	}
+
+	dir := t.TempDir()
+	h := gerrit{r: config.Review{Url: s.URL}}
+
+	err := h.bases(context.Background(), dir, "1-base", "", "", 1, 1, files)
+	assert.Equal(t, nil, err)
+
+	buf, err := os.ReadFile(filepath.Join(dir, "1-base", "lintshell", "c.sh"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("/changes/1/revisions/1/files/lintshell/old.sh/content")), string(buf))
+
+	_, err = os.Stat(filepath.Join(dir, "1-base", "lintshell", "a.sh"))
+	assert.NotEqual(t, nil, err)
+
+	_, err = os.Stat(filepath.Join(dir, "1-base", "lintshell", "missing"))
+	assert.NotEqual(t, nil, err)
+
+	mirror := t.TempDir()
+	work := filepath.Join(t.TempDir(), "work")
+
+	helper := func(dir string, args ...string) string {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+		assert.Equal(t, nil, err)
+		return strings.TrimSpace(string(out))
+	}
+
+	_ = os.MkdirAll(filepath.Join(work, "lintshell"), os.ModePerm)
+	_ = os.WriteFile(filepath.Join(work, "lintshell", "b.sh"), []byte("echo base"), 0600)
+
+	helper(work, "init", "-q")
+	helper(work, "add", "-A")
+	helper(work, "-c", "user.name=lintflow", "-c", "user.email=lintflow@example.com", "commit", "-q", "-m", "base")
+	helper(mirror, "clone", "-q", "--bare", work, "project.git")
+
+	h = gerrit{r: config.Review{Fetch: config.Fetch{Base: true, Mirror: mirror}}}
+
+	err = h.bases(context.Background(), dir, "2-base", "project", helper(work, "rev-parse", "HEAD"), 2, 1, files)
+	assert.Equal(t, nil, err)
+
+	buf, err = os.ReadFile(filepath.Join(dir, "2-base", "lintshell", "b.sh"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("echo base")), string(buf))
+}
+
+// initServer starts a fake gerrit with a change of lintshell/test.sh at patchset 2 and comments,
+// and records the reviews posted by patchset.
+func initServer(t *testing.T, comments map[string]interface{}, reviews map[string]map[string]interface{}) *httptest.Server {
+	patch := "From 0123456789abcdef Mon Sep 17 00:00:00 2001\n" +
+		"diff --git a/lintshell/test.sh b/lintshell/test.sh\n" +
+		"new file mode 100755\n" +
+		"index 0000000..bb54fe5\n" +
+		"--- /dev/null\n" +
+		"+++ b/lintshell/test.sh\n" +
+		"@@ -0,0 +1,3 @@\n" +
+		"+#!/bin/bash\n" +
+		"+\n" +
+		"+echo \"Hello Shell!\"\n"
+
+	content := base64.StdEncoding.EncodeToString([]byte("#!/bin/bash\n\necho \"Hello Shell!\"\n"))
+
+	helper := func(w http.ResponseWriter, data interface{}) {
+		buf, _ := json.Marshal(data)
+		_, _ = w.Write(append([]byte(")]}'\n"), buf...))
+	}
+
+	var mu sync.Mutex
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch path := r.URL.Path; {
+		case path == "/a/changes/":
+			helper(w, []interface{}{map[string]interface{}{
+				"_number":   1,
+				"revisions": map[string]interface{}{commitLocal: map[string]interface{}{"_number": 2}},
+			}})
+		case path == "/a/changes/1/detail":
+			helper(w, map[string]interface{}{"total_comment_count": 0})
+		case path == "/a/changes/1/revisions/2/patch":
+			_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString([]byte(patch))))
+		case path == "/a/changes/1/revisions/2/files/":
+			helper(w, map[string]interface{}{"lintshell/test.sh": map[string]interface{}{}})
+		case path == "/a/accounts/self":
+			helper(w, map[string]interface{}{"_account_id": 1})
+		case path == "/a/changes/1/comments":
+			helper(w, comments)
+		case strings.HasSuffix(path, "/files/lintshell/test.sh/content"):
+			_, _ = w.Write([]byte(content))
+		case r.Method == http.MethodPost && strings.HasSuffix(path, "/review"):
+			buf := map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&buf)
+			mu.Lock()
+			reviews[path] = buf
+			mu.Unlock()
+			helper(w, map[string]interface{}{})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestVoteThreads(t *testing.T) {
+	comment := func(id string, line int, message string) map[string]interface{} {
+		return map[string]interface{}{
+			"id":         id,
+			"author":     map[string]interface{}{"_account_id": 1},
+			"tag":        commentTag + "Lint-Verified",
+			"patch_set":  1,
+			"line":       line,
+			"message":    message,
+			"unresolved": true,
+			"updated":    "2024-01-01 00:00:00.000000000",
+		}
+	}
+
+	comments := map[string]interface{}{
+		"lintshell/test.sh": []interface{}{
+			comment("kept", 3, "error"),
+			comment("fixed", 1, "fixed"),
+			map[string]interface{}{"id": "reply", "in_reply_to": "fixed", "author": map[string]interface{}{"_account_id": 2},
+				"patch_set": 1, "message": "done", "unresolved": true, "updated": "2024-01-02 00:00:00.000000000"},
+			comment("again", 1, "again"),
+			map[string]interface{}{"id": "again-fixed", "in_reply_to": "again", "author": map[string]interface{}{"_account_id": 1},
+				"patch_set": 1, "message": "Fixed in patchset 1", "unresolved": false, "updated": "2024-01-02 00:00:00.000000000"},
+		},
+	}
+
+	reviews := map[string]map[string]interface{}{}
+
+	s := initServer(t, comments, reviews)
+	defer s.Close()
+
+	h := gerrit{r: config.Review{Url: s.URL, User: "user", Pass: "pass", Comment: config.Comment{Unresolved: format.TypeError}}}
+
+	data := []format.Report{
+		{File: "lintshell/test.sh", Line: 3, Type: format.TypeError, Details: "error"},
+		{File: "lintshell/test.sh", Line: 1, Type: format.TypeError, Details: "again"},
+		{File: "lintshell/missing.sh", Line: 1, Type: format.TypeError, Details: "missing"},
+	}
+
+	vote := config.Vote{Label: "Lint-Verified", Approval: "+1", Disapproval: "-1", Message: "Voting"}
+
+	err := h.Vote(context.Background(), commitLocal, data, vote)
+	assert.Equal(t, nil, err)
+
+	replies := reviews["/a/changes/1/revisions/1/review"]["comments"].(map[string]interface{})["lintshell/test.sh"].([]interface{})
+	assert.Equal(t, 1, len(replies))
+	assert.Equal(t, "reply", replies[0].(map[string]interface{})["in_reply_to"])
+	assert.Equal(t, false, replies[0].(map[string]interface{})["unresolved"])
+
+	review := reviews["/a/changes/1/revisions/2/review"]
+	posted := review["comments"].(map[string]interface{})["lintshell/test.sh"].([]interface{})
+	assert.Equal(t, 1, len(posted))
+	assert.Equal(t, "again", posted[0].(map[string]interface{})["message"])
+	assert.Equal(t, float64(1), posted[0].(map[string]interface{})["line"])
+	assert.Equal(t, true, posted[0].(map[string]interface{})["unresolved"])
+	assert.Equal(t, "-1", review["labels"].(map[string]interface{})[vote.Label])
+}
+
+func TestVoteFallback(t *testing.T) {
+	comments := map[string]interface{}{
+		"lintshell/test.sh": []interface{}{
+			map[string]interface{}{
+				"id":         "outside",
+				"author":     map[string]interface{}{"_account_id": 1},
+				"tag":        commentTag + "Lint-Verified",
+				"patch_set":  1,
+				"message":    "Line 5: outside",
+				"unresolved": true,
+				"updated":    "2024-01-01 00:00:00.000000000",
+			},
+		},
+	}
+
+	reviews := map[string]map[string]interface{}{}
+
+	s := initServer(t, comments, reviews)
+	defer s.Close()
+
+	h := gerrit{r: config.Review{Url: s.URL, User: "user", Pass: "pass", Comment: config.Comment{Fallback: fallbackFile}}}
+
+	data := []format.Report{
+		{File: "lintshell/test.sh", Line: 5, Type: format.TypeError, Details: "outside"},
+	}
+
+	vote := config.Vote{Label: "Lint-Verified", Approval: "+1", Disapproval: "-1", Message: "Voting"}
+
+	err := h.Vote(context.Background(), commitLocal, data, vote)
+	assert.Equal(t, nil, err)
+
+	_, ok := reviews["/a/changes/1/revisions/1/review"]
+	assert.Equal(t, false, ok)
+
+	review := reviews["/a/changes/1/revisions/2/review"]
+	assert.Equal(t, nil, review["comments"])
+	assert.Equal(t, "+1", review["labels"].(map[string]interface{})[vote.Label])
+}
+
+func TestScore(t *testing.T) {
+	h := gerrit{}
+
+	vote := config.Vote{
+		Label:       "Lint-Verified",
+		Approval:    "+1",
+		Disapproval: "-1",
+	}
+
+	info := format.Report{File: "name", Line: 1, Type: format.TypeInfo, Details: "info"}
+	warn := format.Report{File: "name", Line: 1, Type: format.TypeWarn, Details: "warn"}
+	fail := format.Report{File: "name", Line: 1, Type: format.TypeError, Details: "error"}
+
+	assert.Equal(t, "+1", h.score(nil, vote))
+	assert.Equal(t, "+1", h.score([]format.Report{info}, vote))
+	assert.Equal(t, "-1", h.score([]format.Report{warn}, vote))
+	assert.Equal(t, "-1", h.score([]format.Report{fail}, vote))
+	assert.Equal(t, "-1", h.score([]format.Report{{Type: "Unknown"}}, vote))
+
+	vote.Policy = config.Policy{
+		Severity:  format.TypeInfo,
+		Threshold: map[string]int{format.TypeWarn: 2},
+		Score:     map[string]string{format.TypeError: "-2", format.TypeWarn: "-1"},
+	}
+
+	assert.Equal(t, "-1", h.score([]format.Report{info}, vote))
+	assert.Equal(t, "+1", h.score([]format.Report{warn}, vote))
+	assert.Equal(t, "-1", h.score([]format.Report{warn, warn}, vote))
+	assert.Equal(t, "-2", h.score([]format.Report{warn, fail}, vote))
+
+	vote.Policy.Severity = format.TypeError
+
+	assert.Equal(t, "+1", h.score([]format.Report{info, warn, warn}, vote))
+	assert.Equal(t, "-2", h.score([]format.Report{fail}, vote))
+}
+
+func TestBuild(t *testing.T) {
+	h := gerrit{}
+
+	patch := "diff --git a/lintshell/test.sh b/lintshell/test.sh\n" +
+		"new file mode 100755\n" +
+		"index 0000000..bb54fe5\n" +
+		"--- /dev/null\n" +
+		"+++ b/lintshell/test.sh\n" +
+		"@@ -0,0 +1,3 @@\n" +
+		"+#!/bin/bash\n" +
+		"+\n" +
+		"+echo \"Hello Shell!\"\n"
+
+	diffs, err := diff.ParseMultiFile(strings.NewReader(patch))
+	assert.Equal(t, nil, err)
+
+	vote := config.Vote{
+		Label:       "Lint-Verified",
+		Approval:    "+1",
+		Disapproval: "-1",
+		Message:     "Voting Lint-Verified by gerrit",
+	}
+
+	build := func(data []format.Report, files map[string]interface{}, limit int) (map[string]interface{}, map[string]interface{}, string) {
+		reports, outside, lines := h.split(data, diffs, files)
+		return h.build(reports, outside, lines, vote, limit, nil)
+	}
+
+	data := []format.Report{
+		{File: "lintshell/test.sh", Line: 1, Type: format.TypeInfo, Details: "info"},
+		{File: "lintshell/test.sh", Line: 3, Type: format.TypeError, Details: "error"},
+		{File: "lintshell/test.sh", Line: 5, Type: format.TypeError, Details: "outside"},
+		{File: "lintshell/none.sh", Line: 1, Type: format.TypeError, Details: "none"},
+	}
+
+	comments, labels, message := build(data, nil, -1)
+	assert.Equal(t, 2, len(comments["lintshell/test.sh"].([]map[string]interface{})))
+	assert.Equal(t, "-1", labels[vote.Label])
+	assert.Equal(t, vote.Message, message)
+
+	comments, labels, message = build(data, nil, 1)
+	assert.Equal(t, 1, len(comments["lintshell/test.sh"].([]map[string]interface{})))
+	assert.Equal(t, "error", comments["lintshell/test.sh"].([]map[string]interface{})[0]["message"])
+	assert.Equal(t, "-1", labels[vote.Label])
+	assert.Contains(t, message, "lintshell/test.sh (Info): 1")
+
+	comments, labels, _ = build(data, nil, 0)
+	assert.Equal(t, 0, len(comments))
+	assert.Equal(t, "-1", labels[vote.Label])
+
+	comments, labels, _ = build(data[:1], nil, -1)
+	assert.Equal(t, 1, len(comments))
+	assert.Equal(t, "+1", labels[vote.Label])
+
+	files := map[string]interface{}{"lintshell/test.sh": map[string]interface{}{}}
+
+	h.r.Comment.Fallback = fallbackFile
+
+	comments, labels, message = build(data, files, -1)
+	assert.Equal(t, 3, len(comments["lintshell/test.sh"].([]map[string]interface{})))
+	assert.Equal(t, "Line 5: outside", comments["lintshell/test.sh"].([]map[string]interface{})[2]["message"])
+	assert.NotContains(t, comments["lintshell/test.sh"].([]map[string]interface{})[2], "line")
+	assert.Contains(t, message, "lintshell/none.sh:1:Error:none")
+	assert.Equal(t, "-1", labels[vote.Label])
+
+	h.r.Comment.Fallback = fallbackMessage
+
+	comments, _, message = build(data, files, -1)
+	assert.Equal(t, 2, len(comments["lintshell/test.sh"].([]map[string]interface{})))
+	assert.Contains(t, message, "lintshell/test.sh:5:Error:outside")
+	assert.Contains(t, message, "lintshell/none.sh:1:Error:none")
+}
+
+func TestSpan(t *testing.T) {
+	h := gerrit{}
+
+	data := format.Report{
+		Line:  3,
+		Range: &format.Range{StartLine: 3, StartColumn: 6, EndLine: 3, EndColumn: 10},
+		Suggestions: []format.Suggestion{
+			{Range: format.Range{StartLine: 3, StartColumn: 6, EndLine: 3, EndColumn: 10}, Text: "\"$1\""},
+		},
+		Details: "Double quote",
+	}
+
+	r := h.span(data, 3)
+	assert.Equal(t, 5, r["start_character"])
+	assert.Equal(t, 9, r["end_character"])
+	assert.Equal(t, "Double quote\n\nSuggestion (line 3):\n    \"$1\"", h.message(data))
+
+	assert.Equal(t, map[string]interface{}(nil), h.span(data, 1))
+
+	data.Range = &format.Range{StartLine: 3, EndLine: 3}
+	assert.Equal(t, map[string]interface{}(nil), h.span(data, 3))
+}
+
+func TestFingerprint(t *testing.T) {
+	h := gerrit{}
+
+	lines := func(revision int, _ string) ([]string, error) {
+		if revision == 1 {
+			return []string{"#!/bin/bash", "echo \"Hello Shell!\""}, nil
+		}
+		return []string{"#!/bin/bash", "", "  echo \"Hello Shell!\""}, nil
+	}
+
+	text1, err := h.text(lines, 1, "lintshell/test.sh", 2)
+	assert.Equal(t, nil, err)
+
+	text2, err := h.text(lines, 2, "lintshell/test.sh", 3)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, h.fingerprint("lintshell/test.sh", text1, "message"), h.fingerprint("lintshell/test.sh", text2, "message"))
+	assert.NotEqual(t, h.fingerprint("lintshell/test.sh", text1, "message"), h.fingerprint("lintshell/test.sh", text2, "other"))
+
+	text, err := h.text(lines, 1, "lintshell/test.sh", 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "#!/bin/bash", text)
+
+	text, err = h.text(lines, 1, "lintshell/test.sh", 10)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "", text)
+}
+
+func TestUnresolved(t *testing.T) {
+	h := gerrit{}
+
+	assert.Equal(t, false, h.unresolved(format.Report{Type: format.TypeError}))
+
+	h.r.Comment.Unresolved = format.TypeError
+
+	assert.Equal(t, true, h.unresolved(format.Report{Type: format.TypeError}))
+	assert.Equal(t, false, h.unresolved(format.Report{Type: format.TypeWarn}))
+	assert.Equal(t, false, h.unresolved(format.Report{Type: format.TypeInfo}))
+
+	h.r.Comment.Unresolved = format.TypeWarn
+
+	assert.Equal(t, true, h.unresolved(format.Report{Type: format.TypeError}))
+	assert.Equal(t, true, h.unresolved(format.Report{Type: format.TypeWarn}))
+	assert.Equal(t, false, h.unresolved(format.Report{Type: format.TypeInfo}))
+}
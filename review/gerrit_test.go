@@ -17,6 +17,7 @@
 package review
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -59,7 +60,7 @@ func TestFetch(t *testing.T) {
 	d, _ := os.Getwd()
 	root := filepath.Join(d, "gerrit-test-fetch")
 
-	dir, repo, files, meta, patch, err := h.Fetch(root, commitGerrit)
+	dir, repo, files, meta, patch, err := h.Fetch(context.Background(), root, commitGerrit, nil)
 	assert.Equal(t, nil, err)
 
 	fmt.Printf("  dir: %s\n", dir)
@@ -80,13 +81,13 @@ func TestQuery(t *testing.T) {
 
 	r := initHandle(t)
 
-	buf, err = r.Query("change:"+strconv.Itoa(changeGerrit), 0)
+	buf, err = r.Query(context.Background(), "change:"+strconv.Itoa(changeGerrit), 0)
 	assert.Equal(t, nil, err)
 
 	ret, _ = json.Marshal(buf)
 	fmt.Printf("change: %s\n", string(ret))
 
-	buf, err = r.Query(queryAfter+" "+queryBefore, 0)
+	buf, err = r.Query(context.Background(), queryAfter+" "+queryBefore, 0)
 	assert.Equal(t, nil, err)
 
 	ret, _ = json.Marshal(buf)
@@ -112,7 +113,7 @@ func TestVote(t *testing.T) {
 		Message:     "Voting Lint-Verified by gerrit",
 	}
 
-	err := h.Vote(commitGerrit, buf, vote)
+	err := h.Vote(context.Background(), commitGerrit, buf, vote)
 	assert.Equal(t, nil, err)
 }
 
@@ -144,7 +145,7 @@ func TestGetMeta(t *testing.T) {
 	_query := map[string]interface{}{}
 	err := json.Unmarshal([]byte(data), &_query)
 
-	_meta, err := h.meta(commit, _query)
+	_meta, err := h.meta(commit, _query, map[string]interface{}{metaConfig: "1-39fe82c.yml"})
 	assert.Equal(t, nil, err)
 
 	dst := make([]byte, base64.StdEncoding.DecodedLen(len(_meta)))
@@ -158,6 +159,7 @@ func TestGetMeta(t *testing.T) {
 	assert.Equal(t, _query["owner"].(map[string]interface{})["name"].(string), buf[metaOwner].(map[string]interface{})[metaName])
 	assert.Equal(t, _query["project"], buf[metaProject])
 	assert.NotEqual(t, "", buf[metaUpdated])
+	assert.Equal(t, "1-39fe82c.yml", buf[metaConfig])
 
 	_, ok := buf[metaRevisions].(map[string]interface{})[commit]
 	assert.Equal(t, true, ok)
@@ -168,10 +170,10 @@ func TestGetMeta(t *testing.T) {
 func TestGetContent(t *testing.T) {
 	h := initHandle(t)
 
-	_, err := h.get(h.urlContent(-1, -1, ""))
+	_, err := h.get(context.Background(), h.urlContent(-1, -1, ""))
 	assert.NotEqual(t, nil, err)
 
-	buf, err := h.get(h.urlContent(changeGerrit, revisionGerrit, "lintshell/test.sh"))
+	buf, err := h.get(context.Background(), h.urlContent(changeGerrit, revisionGerrit, "lintshell/test.sh"))
 	assert.Equal(t, nil, err)
 
 	dst := make([]byte, len(buf))
@@ -184,10 +186,10 @@ func TestGetContent(t *testing.T) {
 func TestGetDetail(t *testing.T) {
 	h := initHandle(t)
 
-	_, err := h.get(h.urlDetail(-1))
+	_, err := h.get(context.Background(), h.urlDetail(-1))
 	assert.NotEqual(t, nil, err)
 
-	buf, err := h.get(h.urlDetail(changeGerrit))
+	buf, err := h.get(context.Background(), h.urlDetail(changeGerrit))
 	assert.Equal(t, nil, err)
 
 	_, err = h.unmarshal(buf)
@@ -197,10 +199,10 @@ func TestGetDetail(t *testing.T) {
 func TestGetFiles(t *testing.T) {
 	h := initHandle(t)
 
-	_, err := h.get(h.urlFiles(-1, -1))
+	_, err := h.get(context.Background(), h.urlFiles(-1, -1))
 	assert.NotEqual(t, nil, err)
 
-	buf, err := h.get(h.urlFiles(changeGerrit, revisionGerrit))
+	buf, err := h.get(context.Background(), h.urlFiles(changeGerrit, revisionGerrit))
 	assert.Equal(t, nil, err)
 
 	_, err = h.unmarshal(buf)
@@ -210,10 +212,10 @@ func TestGetFiles(t *testing.T) {
 func TestGetPatch(t *testing.T) {
 	h := initHandle(t)
 
-	_, err := h.get(h.urlPatch(-1, -1))
+	_, err := h.get(context.Background(), h.urlPatch(-1, -1))
 	assert.NotEqual(t, nil, err)
 
-	buf, err := h.get(h.urlPatch(changeGerrit, revisionGerrit))
+	buf, err := h.get(context.Background(), h.urlPatch(changeGerrit, revisionGerrit))
 	assert.Equal(t, nil, err)
 
 	dst := make([]byte, len(buf))
@@ -226,10 +228,10 @@ func TestGetPatch(t *testing.T) {
 func TestGetQuery(t *testing.T) {
 	h := initHandle(t)
 
-	_, err := h.get(h.urlQuery("commit:-1", []string{"ALL_REVISIONS"}, 0))
+	_, err := h.get(context.Background(), h.urlQuery("commit:-1", []string{"ALL_REVISIONS"}, 0))
 	assert.NotEqual(t, nil, err)
 
-	buf, err := h.get(h.urlQuery("commit:"+commitGerrit, []string{"ALL_REVISIONS"}, 0))
+	buf, err := h.get(context.Background(), h.urlQuery("commit:"+commitGerrit, []string{"ALL_REVISIONS"}, 0))
 	assert.Equal(t, nil, err)
 
 	_, err = h.unmarshalList(buf)
@@ -239,7 +241,7 @@ func TestGetQuery(t *testing.T) {
 func TestPostReview(t *testing.T) {
 	h := initHandle(t)
 
-	err := h.post(h.urlReview(-1, -1), nil)
+	err := h.post(context.Background(), h.urlReview(-1, -1), nil)
 	assert.NotEqual(t, nil, err)
 
 	buf := map[string]interface{}{
@@ -257,6 +259,6 @@ func TestPostReview(t *testing.T) {
 		"message": "Voting Code-Review by gerrit",
 	}
 
-	err = h.post(h.urlReview(changeGerrit, revisionGerrit), buf)
+	err = h.post(context.Background(), h.urlReview(changeGerrit, revisionGerrit), buf)
 	assert.Equal(t, nil, err)
 }
@@ -13,6 +13,8 @@
 package review
 
 import (
+	"context"
+
 	"github.com/pkg/errors"
 
 	"github.com/devops-lintflow/lintflow/config"
@@ -21,12 +23,13 @@ import (
 
 type Review interface {
 	Clean(string) error
-	Fetch(string, string) (string, string, []string, string, string, error)
-	Vote(string, []format.Report, config.Vote) error
+	Fetch(context.Context, string, string, func(string, string, string) bool) (string, string, []string, string, string, error)
+	Vote(context.Context, string, []format.Report, config.Vote) error
 }
 
 type Config struct {
-	Review config.Review
+	Review   config.Review
+	Override string
 }
 
 type review struct {
@@ -37,7 +40,7 @@ type review struct {
 func New(cfg *Config) Review {
 	return &review{
 		cfg: cfg,
-		hdl: &gerrit{cfg.Review},
+		hdl: &gerrit{r: cfg.Review, override: cfg.Override},
 	}
 }
 
@@ -58,12 +61,13 @@ func (r *review) Clean(name string) error {
 }
 
 // nolint:gocritic
-func (r *review) Fetch(root, commit string) (dname, rname string, flist []string, mname, pname string, emsg error) {
+func (r *review) Fetch(ctx context.Context, root, commit string, match func(string, string, string) bool) (dname, rname string,
+	flist []string, mname, pname string, emsg error) {
 	if r.hdl == nil {
 		return "", "", nil, "", "", errors.New("invalid handle")
 	}
 
-	dir, repo, files, meta, patch, err := r.hdl.Fetch(root, commit)
+	dir, repo, files, meta, patch, err := r.hdl.Fetch(ctx, root, commit, match)
 	if err != nil {
 		return "", "", nil, "", "", errors.Wrap(err, "failed to fetch")
 	}
@@ -71,12 +75,12 @@ func (r *review) Fetch(root, commit string) (dname, rname string, flist []string
 	return dir, repo, files, meta, patch, nil
 }
 
-func (r *review) Vote(commit string, data []format.Report, vote config.Vote) error {
+func (r *review) Vote(ctx context.Context, commit string, data []format.Report, vote config.Vote) error {
 	if r.hdl == nil {
 		return errors.New("invalid handle")
 	}
 
-	if err := r.hdl.Vote(commit, data, vote); err != nil {
+	if err := r.hdl.Vote(ctx, commit, data, vote); err != nil {
 		return errors.Wrap(err, "failed to vote")
 	}
 
@@ -17,6 +17,7 @@
 package review
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -81,7 +82,7 @@ func TestReview(t *testing.T) {
 	ti := time.Now()
 	root := filepath.Join(d, "gerrit-"+ti.Format("2006-01-02"))
 
-	dir, repo, files, meta, patch, err := r.Fetch(root, commitGerrit)
+	dir, repo, files, meta, patch, err := r.Fetch(context.Background(), root, commitGerrit, nil)
 	assert.Equal(t, nil, err)
 
 	fmt.Printf("  dir: %s\n", dir)
@@ -105,7 +106,7 @@ func TestReview(t *testing.T) {
 		Message:     "Voting Lint-Verified by review",
 	}
 
-	err = r.Vote(commitGerrit, buf, vote)
+	err = r.Vote(context.Background(), commitGerrit, buf, vote)
 	assert.Equal(t, nil, err)
 
 	err = r.Clean(root)